@@ -0,0 +1,95 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+)
+
+//  attachCmd follows the output of a detached environment
+var attachCmd = &cobra.Command{
+	Use:        "attach",
+	SuggestFor: []string{"logs", "dev"},
+	Short:      "Follow the output of your app running in background",
+	Long: `Print the output of the app started with 'nhost dev --detach',
+and keep following it until you press Ctrl+C.
+
+Detaching with Ctrl+C doesn't stop your app. Use 'nhost stop' for that.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		daemon, err := nhost.LoadDaemon()
+		if err != nil || !util.ProcessRunning(daemon.PID) {
+			status.Info("Start your app in background with `nhost dev --detach`")
+			status.Fatal("No app found running in background")
+		}
+
+		file, err := os.Open(nhost.DAEMON_LOG_PATH)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to open the output of your app")
+		}
+		defer file.Close()
+
+		//  Detach on Ctrl+C, without affecting the background process
+		detached := make(chan os.Signal, 1)
+		signal.Notify(detached, os.Interrupt, syscall.SIGTERM)
+
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+
+			//  Print whatever has been written since the last read
+			if _, err := io.Copy(os.Stdout, file); err != nil {
+				log.Debug(err)
+				status.Fatal("Failed to read the output of your app")
+			}
+
+			select {
+			case <-detached:
+				status.Infoln("Detached. Your app is still running in background")
+				return
+			case <-ticker.C:
+				if !util.ProcessRunning(daemon.PID) {
+					io.Copy(os.Stdout, file)
+					status.Infoln("Your app has stopped")
+					return
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+}
@@ -0,0 +1,207 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nhost/cli/logger"
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+)
+
+//	Maximum time to wait for a detached environment to become active,
+//	including the download of images on it's first run
+const DETACH_TIMEOUT = 15 * time.Minute
+
+//	Spawns `nhost dev` as a supervised background process,
+//	and waits for it to report that the app is active.
+func runDetached() error {
+
+	log.Debug("Starting detached environment")
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	//	Merged output of the background process.
+	//	Truncated on every launch, so that `nhost attach` only shows the current session.
+	output, err := os.Create(nhost.DAEMON_LOG_PATH)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	//	Remove any stale state left behind by a crashed process
+	nhost.RemoveDaemon()
+
+	args := []string{"dev", "--supervised", "--no-browser", "--port", env.Port}
+	if logger.DEBUG {
+		args = append(args, "--debug")
+	}
+
+	process := exec.Command(executable, args...)
+	process.Dir = util.WORKING_DIR
+	process.Stdout = output
+	process.Stderr = output
+	util.Detach(process)
+
+	if err := process.Start(); err != nil {
+		return err
+	}
+
+	pid := process.Process.Pid
+
+	//	Reap the process if it exits, so that it doesn't linger as a zombie.
+	//	It keeps running after we return, since it has been detached.
+	exited := make(chan error, 1)
+	go func() {
+		exited <- process.Wait()
+	}()
+
+	status.Executing("Starting your app in background")
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	timeout := time.After(DETACH_TIMEOUT)
+
+wait:
+	for {
+		select {
+		case err := <-exited:
+			log.Debug(err)
+			status.Errorln("Background process exited before your app became active")
+			printLastLines(nhost.DAEMON_LOG_PATH, 20)
+			return errors.New("detached environment exited")
+
+		case <-timeout:
+			status.Errorln(fmt.Sprintf("Your app didn't become active within %v", DETACH_TIMEOUT))
+			printLastLines(nhost.DAEMON_LOG_PATH, 20)
+			if err := util.Terminate(pid); err != nil {
+				log.Debug(err)
+			}
+			return errors.New("timed out waiting for detached environment")
+
+		case <-ticker.C:
+			daemon, err := nhost.LoadDaemon()
+			if err == nil && daemon.PID == pid && daemon.Status == nhost.DAEMON_ACTIVE {
+				break wait
+			}
+		}
+	}
+
+	status.Success(fmt.Sprintf("Your app is running in background at %shttp://localhost:%s%s", util.Blue, env.Port, util.Reset))
+	status.Infoln("Follow it with `nhost attach`, check it with `nhost status` and stop it with `nhost stop`")
+
+	if !noBrowser {
+		go openbrowser(fmt.Sprintf("http://localhost:%s", env.Port))
+
+		//	Give the browser a moment to launch before we exit
+		time.Sleep(1 * time.Second)
+	}
+
+	return nil
+}
+
+//	Saves the state of the current background process to .nhost/daemon.yaml
+func saveDaemon(state string) error {
+
+	//	Preserve the launch time across status updates
+	started := time.Now()
+	if saved, err := nhost.LoadDaemon(); err == nil && saved.PID == os.Getpid() {
+		started = saved.Started
+	}
+
+	daemon := nhost.Daemon{
+		PID:     os.Getpid(),
+		Port:    env.Port,
+		Branch:  nhost.GetCurrentBranch(),
+		Status:  state,
+		Log:     util.Rel(nhost.DAEMON_LOG_PATH),
+		Started: started,
+	}
+
+	return daemon.Save()
+}
+
+//	Gracefully stops the background process of a detached environment, if one is running.
+//	Returns true if a running process was found.
+func stopDaemon() (bool, error) {
+
+	daemon, err := nhost.LoadDaemon()
+	if err != nil || !util.ProcessRunning(daemon.PID) {
+		nhost.RemoveDaemon()
+		return false, nil
+	}
+
+	log.WithField("pid", daemon.PID).Debug("Stopping detached environment")
+
+	if err := util.Terminate(daemon.PID); err != nil {
+		return true, err
+	}
+
+	//	Wait for the process to finish it's cleanup
+	for counter := 0; counter < 60; counter++ {
+		if !util.ProcessRunning(daemon.PID) {
+			break
+		}
+		time.Sleep(1 * time.Second)
+	}
+
+	if util.ProcessRunning(daemon.PID) {
+		return true, fmt.Errorf("process %d is still running", daemon.PID)
+	}
+
+	return true, nhost.RemoveDaemon()
+}
+
+//	Prints last N lines of the given file
+func printLastLines(path string, count int) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > count {
+			lines = lines[1:]
+		}
+	}
+
+	fmt.Println(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,411 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/manifoldco/promptui"
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+
+	//  branch whose snapshots are listed or restored
+	snapshotBranch string
+
+	//  branch whose data is copied into current branch
+	copyFrom string
+
+	//  only list the data which would be pruned
+	pruneDryRun bool
+)
+
+//  dataCmd manages the local data of git branches
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Manage local data of your app",
+	Long: `Database and storage data of your local app is kept
+separately for every git branch in .nhost/<branch>.
+
+Save it in snapshots, restore them later,
+or copy it between branches.`,
+}
+
+//  dataSnapshotCmd archives the data of current branch
+var dataSnapshotCmd = &cobra.Command{
+	Use:   "snapshot [name]",
+	Short: "Save the data of current branch in a snapshot",
+	Long: `Archive the database and storage data of current branch
+into a timestamped snapshot in .nhost/.snapshots/<branch>.
+
+If your app is running, database and storage
+are stopped while the snapshot is being created.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		if !util.PathExists(nhost.BranchDir(nhost.GetBranch())) {
+			status.Fatal(fmt.Sprintf("No data found for branch %s", nhost.GetBranch()))
+		}
+
+		resume := pauseDataServices()
+		status.Executing("Creating snapshot")
+		snapshot, err := nhost.CreateSnapshot(env.DataRunner(), name)
+		resume()
+
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to create snapshot")
+		}
+
+		status.Successln(fmt.Sprintf("Saved snapshot %s (%s)", snapshot.Name, util.HumanSize(snapshot.Size)))
+	},
+}
+
+//  dataRestoreCmd replaces the data of current branch with a snapshot
+var dataRestoreCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Restore the data of current branch from a snapshot",
+	Long: `Replace the database and storage data of current branch
+with a snapshot. Either the full name of the snapshot,
+or it's name without timestamp for the latest one, can be used.
+
+Snapshots of other branches can be restored with --branch.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		branch := snapshotBranch
+		if branch == "" {
+			branch = nhost.GetBranch()
+		}
+
+		snapshot, err := nhost.FindSnapshot(branch, args[0])
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(err.Error())
+		}
+
+		//  if the user has not pre-approved the restore,
+		//  take the user's approval manually
+		if !approve {
+			status.Warnln(fmt.Sprintf("Current data of branch %s will be replaced with %s", nhost.GetBranch(), snapshot.Name))
+
+			prompt := promptui.Prompt{
+				Label:     "Are you sure you want to continue",
+				IsConfirm: true,
+			}
+
+			if _, err := prompt.Run(); err != nil {
+				os.Exit(0)
+			}
+		}
+
+		resume := pauseDataServices()
+		status.Executing("Restoring snapshot")
+		err = snapshot.Restore(env.DataRunner())
+		resume()
+
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to restore snapshot")
+		}
+
+		status.Successln(fmt.Sprintf("Restored snapshot %s", snapshot.Name))
+	},
+}
+
+//  dataCopyCmd replaces the data of current branch with another branch's data
+var dataCopyCmd = &cobra.Command{
+	Use:   "copy --from <branch>",
+	Short: "Copy the data of another branch into current branch",
+	Long: `Replace the database and storage data of current branch
+with a copy of another branch's data.
+
+To do this automatically for branches which have never run,
+set 'copy_from' under 'branches' in nhost/config.yaml
+to a branch name, or to 'previous' for the branch checked out before.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		branch := nhost.GetBranch()
+
+		if copyFrom == "" {
+			status.Fatal("Mention the branch to copy the data from with `--from`")
+		}
+
+		if copyFrom == branch {
+			status.Fatal(fmt.Sprintf("Branch %s is already checked out", branch))
+		}
+
+		if !util.PathExists(nhost.BranchDir(copyFrom)) {
+			status.Fatal(fmt.Sprintf("No data found for branch %s", copyFrom))
+		}
+
+		//  if the user has not pre-approved replacing existing data,
+		//  take the user's approval manually
+		if !approve && util.PathExists(nhost.BranchDir(branch)) {
+			status.Warnln(fmt.Sprintf("Current data of branch %s will be replaced with data of %s", branch, copyFrom))
+
+			prompt := promptui.Prompt{
+				Label:     "Are you sure you want to continue",
+				IsConfirm: true,
+			}
+
+			if _, err := prompt.Run(); err != nil {
+				os.Exit(0)
+			}
+		}
+
+		resume := pauseDataServices()
+		status.Executing(fmt.Sprintf("Copying data of branch %s", copyFrom))
+		err := nhost.CopyBranchData(env.DataRunner(), copyFrom, branch)
+		resume()
+
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to copy data")
+		}
+
+		status.Successln(fmt.Sprintf("Copied data of branch %s into %s", copyFrom, branch))
+	},
+}
+
+//  dataListCmd lists the snapshots of current branch
+var dataListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List snapshots of current branch",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		branch := snapshotBranch
+		if branch == "" {
+			branch = nhost.GetBranch()
+		}
+
+		snapshots, err := nhost.ListSnapshots(branch)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to read snapshots")
+		}
+
+		if len(snapshots) == 0 {
+			status.Infoln(fmt.Sprintf("No snapshots found for branch %s. Create one with `nhost data snapshot`", branch))
+			return
+		}
+
+		p := newPrinter()
+		p.print("header", "", "")
+
+		fmt.Fprintln(p, "Snapshot\tCreated\tSize")
+		fmt.Fprintln(p, "--------\t-------\t----")
+
+		for _, item := range snapshots {
+			fmt.Fprintf(p, "%s\t%s\t%s\n", item.Name, item.Created.Format(time.RFC822), util.HumanSize(item.Size))
+		}
+
+		p.close()
+	},
+}
+
+//  dataPruneCmd deletes the data of branches which don't exist anymore
+var dataPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the data of deleted git branches",
+	Long: `Delete the database and storage data in .nhost/<branch>
+of every branch which doesn't exist in your local git repository anymore.
+
+Snapshots of those branches are kept,
+and can still be restored with ` + "`nhost data restore --branch`" + `.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		//  Data is sized and deleted in a container
+		if err := env.Init(); err != nil {
+			log.Debug(err)
+			status.Fatal("Make sure Docker is running, it's required to manage the data of your app")
+		}
+
+		run := env.DataRunner()
+
+		stale, err := nhost.StaleData(run)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to find the data of deleted branches")
+		}
+
+		if len(stale) == 0 {
+			status.Infoln("No data of deleted branches found")
+			return
+		}
+
+		p := newPrinter()
+		p.print("header", "", "")
+
+		fmt.Fprintln(p, "Branch\tSize")
+		fmt.Fprintln(p, "------\t----")
+
+		var total int64
+		for _, item := range stale {
+			total += item.Size
+			fmt.Fprintf(p, "%s\t%s\n", item.Branch, util.HumanSize(item.Size))
+		}
+
+		p.close()
+
+		if pruneDryRun {
+			status.Infoln(fmt.Sprintf("%s of data can be freed with `nhost data prune`", util.HumanSize(total)))
+			return
+		}
+
+		//  if the user has not pre-approved the deletion,
+		//  take the user's approval manually
+		if !approve {
+			status.Warnln(fmt.Sprintf("Data of %d deleted branches will be permanently deleted", len(stale)))
+
+			prompt := promptui.Prompt{
+				Label:     "Are you sure you want to continue",
+				IsConfirm: true,
+			}
+
+			if _, err := prompt.Run(); err != nil {
+				os.Exit(0)
+			}
+		}
+
+		status.Executing("Deleting data of deleted branches")
+		for _, item := range stale {
+			if err := nhost.DeleteBranchData(run, item.Branch); err != nil {
+				log.WithField("branch", item.Branch).Debug(err)
+				status.Fatal(fmt.Sprintf("Failed to delete the data of branch %s", item.Branch))
+			}
+		}
+
+		status.Successln(fmt.Sprintf("Freed %s", util.HumanSize(total)))
+	},
+}
+
+//  Warns if the data of deleted branches is larger
+//  than the limit configured in nhost/config.yaml
+func warnStaleData() {
+
+	limit := env.Config.Branches.StaleDataLimit
+	if limit == "" {
+		return
+	}
+
+	max, err := util.ParseSize(limit)
+	if err != nil {
+		log.Debug(err)
+		status.Warnln(fmt.Sprintf("Invalid stale_data_limit in config: %s", limit))
+		return
+	}
+
+	stale, err := nhost.StaleData(env.DataRunner())
+	if err != nil {
+		log.Debug(err)
+		return
+	}
+
+	var total int64
+	for _, item := range stale {
+		total += item.Size
+	}
+
+	if total > max {
+		status.Warnln(fmt.Sprintf("Data of %d deleted branches takes %s, free it with `nhost data prune`", len(stale), util.HumanSize(total)))
+	}
+}
+
+//  Stops the running database and storage containers of the app,
+//  so that their data can be safely read or replaced.
+//  Returns a function which starts them again.
+//
+//  Data is read and replaced in a container,
+//  so docker is required even if the app isn't running.
+func pauseDataServices() func() {
+
+	var paused []string
+
+	if err := env.Init(); err != nil {
+		log.Debug(err)
+		status.Fatal("Make sure Docker is running, it's required to manage the data of your app")
+	}
+
+	for _, name := range []string{"postgres", "minio"} {
+
+		service := env.Config.Services[name]
+		if service == nil || service.ID == "" {
+			continue
+		}
+
+		if state, err := service.State(env.Docker, env.Context); err != nil || state != "running" {
+			continue
+		}
+
+		status.Executing(fmt.Sprintf("Stopping %s", name))
+		if err := service.Stop(env.Docker, env.Context); err != nil {
+			log.WithField("service", name).Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to stop %s", name))
+		}
+
+		paused = append(paused, service.ID)
+	}
+
+	return func() {
+		for _, id := range paused {
+			if err := env.Docker.ContainerStart(env.Context, id, types.ContainerStartOptions{}); err != nil {
+				log.Debug(err)
+				status.Errorln("Failed to start your database and storage again, restart your app with `nhost dev`")
+			}
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(dataCmd)
+	dataCmd.AddCommand(dataSnapshotCmd)
+	dataCmd.AddCommand(dataRestoreCmd)
+	dataCmd.AddCommand(dataListCmd)
+	dataCmd.AddCommand(dataCopyCmd)
+	dataCmd.AddCommand(dataPruneCmd)
+
+	dataRestoreCmd.Flags().StringVarP(&snapshotBranch, "branch", "b", "", "Branch to restore the snapshot of")
+	dataRestoreCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass the confirmation prompt")
+	dataListCmd.Flags().StringVarP(&snapshotBranch, "branch", "b", "", "Branch to list the snapshots of")
+	dataCopyCmd.Flags().StringVar(&copyFrom, "from", "", "Branch to copy the data from")
+	dataCopyCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass the confirmation prompt")
+	dataPruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Only list the data which would be deleted")
+	dataPruneCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass the confirmation prompt")
+}
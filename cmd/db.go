@@ -0,0 +1,277 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/manifoldco/promptui"
+	"github.com/nhost/cli/hasura"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+
+	//  dump only the schema, or only the data
+	schemaOnly bool
+	dataOnly   bool
+
+	//  schemas and tables to dump, all of them if empty
+	dumpSchemas []string
+	dumpTables  []string
+
+	//  file to write the dump to, instead of stdout
+	dumpOutput string
+)
+
+//  dbCmd manages the database of the app
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage the database of your app",
+	Long: `Dump the database of your local or production app,
+restore dumps into your local database, or reset it.`,
+}
+
+//  dbDumpCmd dumps the database through Hasura's pg_dump
+var dbDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Dump the database of your app",
+	Long: `Dump the schema and data of your app's database as SQL,
+using pg_dump through Hasura.
+
+Use --prod to dump the database of your linked production app.`,
+	Example: `  nhost db dump -o dump.sql
+  nhost db dump --schema-only --schema public
+  nhost db dump --data-only --table public.todos --prod -o todos.sql`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if schemaOnly && dataOnly {
+			status.Fatal("Mention either `--schema-only` or `--data-only`, not both")
+		}
+
+		var client *hasura.Client
+		if production {
+			client = &hasura.Client{Client: &http.Client{}}
+			client.Endpoint, client.AdminSecret = productionHasura(cmd, args)
+		} else {
+			client = localHasuraClient()
+		}
+
+		//  Keep stdout clean for the dump
+		if dumpOutput != "" {
+			status.Executing("Dumping database")
+		}
+
+		dump, err := client.PGDump(dumpOptions())
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to dump database: %v", err))
+		}
+
+		if dumpOutput == "" {
+			os.Stdout.Write(dump)
+			return
+		}
+
+		if err := ioutil.WriteFile(dumpOutput, dump, 0644); err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to write %s", dumpOutput))
+		}
+
+		status.Successln(fmt.Sprintf("Saved dump to %s (%s)", dumpOutput, util.HumanSize(int64(len(dump)))))
+	},
+}
+
+//  dbRestoreCmd loads a dump into the local database
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Load a dump into your local database",
+	Long: `Load an SQL dump, like one created with 'nhost db dump',
+into the database of your running app, with psql.
+
+The dump is loaded in a single transaction,
+which is rolled back on the first error.`,
+	Example: `  nhost db dump --prod -o production.sql
+  nhost db restore production.sql`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to read %s", args[0]))
+		}
+		defer file.Close()
+
+		postgres := localPostgres()
+
+		//  if the user has not pre-approved the restore,
+		//  take the user's approval manually
+		if !approve {
+			status.Warnln("The dump will be loaded into your local database. Save it first with `nhost data snapshot`, to undo this")
+
+			prompt := promptui.Prompt{
+				Label:     "Are you sure you want to continue",
+				IsConfirm: true,
+			}
+
+			if _, err := prompt.Run(); err != nil {
+				os.Exit(0)
+			}
+		}
+
+		user, password := databaseCredentials()
+		execEnv = append(execEnv, "PGPASSWORD="+password)
+
+		status.Executing(fmt.Sprintf("Restoring %s", args[0]))
+		code, err := execute(postgres, []string{
+			"psql", "-U", user, "-p", fmt.Sprint(postgres.Port),
+			"--quiet", "--single-transaction", "--set", "ON_ERROR_STOP=1",
+			"--file", "-", "postgres",
+		}, file)
+
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to restore the dump")
+		}
+
+		if code != 0 {
+			status.Errorln("Failed to restore the dump, no changes were made")
+			os.Exit(code)
+		}
+
+		status.Successln(fmt.Sprintf("Restored %s", args[0]))
+	},
+}
+
+//  dbResetCmd resets the local database to a clean schema
+var dbResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Reset your local database to a clean schema",
+	Long: `Drop all tables, views, functions and types of your app
+from the database of your running app, and apply
+migrations, metadata and seeds again.
+
+Users and files, in the auth and storage schemas, are kept.
+Containers aren't recreated, so it's much faster than 'nhost purge --data'.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		client := localHasuraClient()
+
+		//  if the user has not pre-approved the reset,
+		//  take the user's approval manually
+		if !approve {
+			status.Warnln("All data of your app, except users and files, will be deleted. Save it first with `nhost data snapshot`, to undo this")
+
+			prompt := promptui.Prompt{
+				Label:     "Are you sure you want to continue",
+				IsConfirm: true,
+			}
+
+			if _, err := prompt.Run(); err != nil {
+				os.Exit(0)
+			}
+		}
+
+		//  Migrations and metadata are applied without the Hasura CLI
+		env.Hasura = client
+
+		env.ExecutionContext, env.ExecutionCancel = context.WithCancel(env.Context)
+		defer env.ExecutionCancel()
+
+		status.Executing("Resetting database")
+		if err := env.Reset(); err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to reset database")
+		}
+
+		status.Successln("Database reset, with migrations, metadata and seeds applied")
+	},
+}
+
+//  Returns the options of pg_dump for the flags
+func dumpOptions() []string {
+
+	//  Skip ownership and privileges,
+	//  which differ between local and production databases
+	response := []string{"--no-owner", "--no-acl"}
+
+	if schemaOnly {
+		response = append(response, "--schema-only")
+	}
+
+	if dataOnly {
+		response = append(response, "--data-only")
+	}
+
+	for _, item := range dumpSchemas {
+		response = append(response, "--schema", item)
+	}
+
+	for _, item := range dumpTables {
+		response = append(response, "--table", item)
+	}
+
+	return response
+}
+
+//  Returns the endpoint of running local Hasura
+func localHasura() string {
+
+	//  Initialize the runtime environment
+	if err := env.Init(); err != nil {
+		log.Debug(err)
+		status.Fatal(util.WarnDockerNotFound)
+	}
+
+	service := env.Config.Services["hasura"]
+	if service == nil || service.ID == "" {
+		status.Fatal("Make sure your Nhost environment is running with `nhost dev`")
+	}
+
+	return service.Address
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbDumpCmd)
+	dbCmd.AddCommand(dbRestoreCmd)
+	dbCmd.AddCommand(dbResetCmd)
+
+	dbDumpCmd.Flags().BoolVar(&schemaOnly, "schema-only", false, "Only dump the schema, without data")
+	dbDumpCmd.Flags().BoolVar(&dataOnly, "data-only", false, "Only dump the data, without schema")
+	dbDumpCmd.Flags().StringArrayVarP(&dumpSchemas, "schema", "n", nil, "Schema to dump, can be repeated")
+	dbDumpCmd.Flags().StringArrayVarP(&dumpTables, "table", "t", nil, "Table to dump, like public.todos, can be repeated")
+	dbDumpCmd.Flags().StringVarP(&dumpOutput, "output", "o", "", "File to write the dump to, instead of stdout")
+	dbDumpCmd.Flags().BoolVar(&production, "prod", false, "Dump the database of the linked production app")
+	dbRestoreCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass the confirmation prompt")
+	dbResetCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass the confirmation prompt")
+}
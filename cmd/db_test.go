@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDumpOptions(t *testing.T) {
+
+	schemaOnly, dumpSchemas, dumpTables = true, []string{"public"}, []string{"public.todos", "auth.users"}
+	defer func() {
+		schemaOnly, dumpSchemas, dumpTables = false, nil, nil
+	}()
+
+	want := []string{"--no-owner", "--no-acl", "--schema-only", "--schema", "public", "--table", "public.todos", "--table", "auth.users"}
+	if got := dumpOptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("dumpOptions() = %q, want %q", got, want)
+	}
+}
@@ -50,6 +50,12 @@ var (
 	//  through a tunnel
 	expose bool
 
+	//  run the development environment in background
+	detach bool
+
+	//  set on the background process spawned by `nhost dev --detach`
+	supervised bool
+
 	//  signal interruption channel
 	stop = make(chan os.Signal)
 )
@@ -81,7 +87,7 @@ var (
 
 //  devCmd represents the dev command
 var devCmd = &cobra.Command{
-	Use:        "dev [-p port]",
+	Use:        "dev [-p port] [--detach]",
 	Aliases:    []string{"up"},
 	SuggestFor: []string{"list", "init"},
 	Short:      "Start local development environment",
@@ -104,6 +110,16 @@ var devCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 
+		//	If the app is already running in background,
+		//	don't launch a second environment on top of it.
+		if !supervised {
+			if daemon, err := nhost.LoadDaemon(); err == nil && util.ProcessRunning(daemon.PID) {
+				status.Info(fmt.Sprintf("Your app is already running in background at %shttp://localhost:%s%s", util.Blue, daemon.Port, util.Reset))
+				status.Infoln("Follow it with `nhost attach`, or stop it with `nhost stop`")
+				return
+			}
+		}
+
 		//	Fixes GH #129, by moving it from pre-run to run.
 		//
 		//	If the default port is not available,
@@ -113,8 +129,30 @@ var devCmd = &cobra.Command{
 			status.Fatal(fmt.Sprintf("port %s not available", env.Port))
 		}
 
+		//	Spawn the environment as a background process,
+		//	and return the terminal to the user.
+		if detach {
+			if err := runDetached(); err != nil {
+				log.Debug(err)
+				status.Fatal("Failed to start your app in background")
+			}
+			return
+		}
+
+		//	Detached environments are resumed by the next `nhost dev`,
+		//	so only stop their containers on shutdown.
+		if supervised {
+			env.KeepContainers = true
+			if err := saveDaemon(nhost.DAEMON_STARTING); err != nil {
+				log.Debug(err)
+			}
+		}
+
 		var err error
 
+		//	Metadata which fails to apply is fixed while the app keeps running
+		env.AllowInconsistent = true
+
 		//  Initialize the runtime environment
 		if err = env.Init(); err != nil {
 			log.Debug(err)
@@ -129,6 +167,14 @@ var devCmd = &cobra.Command{
 			status.Fatal("Failed to read Nhost config")
 		}
 
+		//  Start a branch which has never run with data of it's parent branch, if configured
+		env.InheritData("")
+
+		warnStaleData()
+
+		//  Capture the output of functions and proxy for `nhost logs`
+		functionsOutput = createLog("functions")
+
 		//	Start the Watcher
 		go env.Watcher.Start()
 
@@ -145,6 +191,11 @@ var devCmd = &cobra.Command{
 
 			//  Cleanup the environment
 			env.Cleanup()
+			closeLogs()
+
+			if supervised {
+				nhost.RemoveDaemon()
+			}
 
 			end_waiter.Done()
 			os.Exit(0)
@@ -157,6 +208,7 @@ var devCmd = &cobra.Command{
 			Port:        env.Port,
 			Environment: &env,
 			Log:         log,
+			AccessLog:   createLog("proxy"),
 		})
 
 		//  Register Functions as a service to the reverse proxy to route it's UI port
@@ -201,10 +253,19 @@ var devCmd = &cobra.Command{
 			log.Debug(err)
 			status.Errorln("Failed to initialize your environment")
 			env.Cleanup()
+			closeLogs()
+			if supervised {
+				nhost.RemoveDaemon()
+			}
 			end_waiter.Done()
 			return
 		}
 
+		//  Reuse the ports of services on next runs
+		if err := env.Config.SavePorts(); err != nil {
+			log.WithField("path", nhost.PortsPath()).Debug(err)
+		}
+
 		//
 		//  Everything after this point,
 		//  needs to be executed only the first time
@@ -285,33 +346,7 @@ var devCmd = &cobra.Command{
 		}()
 
 		//	Register all services along with their proxy routes.
-		for name, item := range env.Config.Services {
-
-			var routes []proxy.Route
-
-			switch name {
-			case "auth":
-				routes = []proxy.Route{{Name: "Authentication", Source: "/", Destination: "/v1/auth/", Show: true}}
-			case "storage":
-				routes = []proxy.Route{{Name: "Storage", Source: "/v1/", Destination: "/v1/storage/", Show: true}}
-			case "hasura":
-				routes = []proxy.Route{
-					{Name: "GraphQL", Source: "/v1/graphql", Destination: "/v1/graphql", Show: true},
-					{Name: "Query", Source: "/v2/query", Destination: "/v2/query"},
-					{Name: "Metadata", Source: "/v1/metadata", Destination: "/v1/metadata"},
-					{Name: "Config", Source: "/v1/config", Destination: "/v1/config"},
-				}
-			}
-
-			if len(routes) > 0 {
-				reverseproxy.AddService(&proxy.Service{
-					Name:    item.Name,
-					Routes:  routes,
-					Port:    fmt.Sprint(item.Port),
-					Address: nhost.GetAddress(item),
-				})
-			}
-		}
+		registerServices(reverseproxy)
 
 		//  Register Hasura Console as a service to the reverse proxy to route it's UI port
 		reverseproxy.AddService(&proxy.Service{
@@ -321,25 +356,22 @@ var devCmd = &cobra.Command{
 			Address: fmt.Sprintf("http://localhost:%v", consolePort),
 		})
 
-		go func() {
-
-			//	Issue proxies of all registered services
-			if err := reverseproxy.IssueAll(env.Context); err != nil {
-				log.WithField("component", "reverseproxy").Debug(err)
-				status.Errorln("Failed to issue proxies")
-			}
+		go serveProxy(reverseproxy)
 
-			//  Before launching, register the proxy server in our environment
-			env.Servers = append(env.Servers, reverseproxy.Server)
+		//  Update environment state
+		env.UpdateState(environment.Active)
 
-			//  Now start the server
-			if err := reverseproxy.ListenAndServe(); err != nil {
-				log.WithFields(logrus.Fields{"component": "proxy", "value": env.Port}).Debug(err)
+		//	Let `nhost dev --detach` know that the app is ready
+		if supervised {
+			if err := saveDaemon(nhost.DAEMON_ACTIVE); err != nil {
+				log.Debug(err)
 			}
-		}()
+		}
 
-		//  Update environment state
-		env.UpdateState(environment.Active)
+		//  Metadata with inconsistent objects has been applied without them
+		if !supervised {
+			resolveInconsistencies()
+		}
 
 		//  wait for Ctrl+C
 		end_waiter.Wait()
@@ -360,6 +392,44 @@ func newPrinter() *Printer {
 	}
 }
 
+//	Registers all services of the environment, along with their proxy routes.
+func registerServices(reverseproxy *proxy.Server) {
+
+	for name, item := range env.Config.Services {
+
+		var routes []proxy.Route
+		for _, route := range nhost.ProxyRoutes(name, item) {
+			routes = append(routes, proxy.Route{Name: route.Name, Source: route.Source, Destination: route.Destination, Show: !route.Hidden})
+		}
+
+		if len(routes) > 0 {
+			reverseproxy.AddService(&proxy.Service{
+				Name:    item.Name,
+				Routes:  routes,
+				Port:    fmt.Sprint(item.Port),
+				Address: nhost.GetAddress(item),
+			})
+		}
+	}
+}
+
+//	Issues proxies of all registered services, and starts the proxy server.
+func serveProxy(reverseproxy *proxy.Server) {
+
+	if err := reverseproxy.IssueAll(env.Context); err != nil {
+		log.WithField("component", "reverseproxy").Debug(err)
+		status.Errorln("Failed to issue proxies")
+	}
+
+	//  Before launching, register the proxy server in our environment
+	env.Servers = append(env.Servers, reverseproxy.Server)
+
+	//  Now start the server
+	if err := reverseproxy.ListenAndServe(); err != nil {
+		log.WithFields(logrus.Fields{"component": "proxy", "value": env.Port}).Debug(err)
+	}
+}
+
 func (p *Printer) print(loc, head, tail string) {
 
 	switch loc {
@@ -390,6 +460,9 @@ func init() {
 	//  and all subcommands, e.g.:
 	devCmd.PersistentFlags().StringVarP(&env.Port, "port", "p", "1337", "Port for dev proxy")
 	devCmd.PersistentFlags().BoolVar(&noBrowser, "no-browser", false, "Don't open browser windows automatically")
+	devCmd.Flags().BoolVar(&detach, "detach", false, "Run the app in background")
+	devCmd.Flags().BoolVar(&supervised, "supervised", false, "Run as the background process of a detached app")
+	devCmd.Flags().MarkHidden("supervised")
 	//	devCmd.PersistentFlags().BoolVarP(&expose, "expose", "e", false, "Expose local environment to public internet")
 
 	//  Cobra supports local flags which will only run when this command
@@ -128,13 +128,16 @@ func Test_Pipeline(t *testing.T) {
 	}
 }
 
+//	Probes every service with a container, using it's configured health check
 func healthCheck() error {
 
 	for _, item := range env.Config.Services {
-		if item.HealthEndpoint != "" {
-			if code := check200(item.Address + item.HealthEndpoint); code != 200 {
-				return fmt.Errorf("%s: expected 200 response, got - %v", item.Name, code)
-			}
+		if item.HealthCheck == nil || item.NoContainer {
+			continue
+		}
+
+		if err := item.Probe(env.Docker, env.Context); err != nil {
+			return fmt.Errorf("%s: health check failed: %v", item.Name, err)
 		}
 	}
 
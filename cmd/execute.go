@@ -25,18 +25,19 @@ SOFTWARE.
 package cmd
 
 import (
-	"bytes"
 	"context"
-	"io/ioutil"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/docker/docker/api/types"
-	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/manifoldco/promptui"
 	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
@@ -44,24 +45,43 @@ var (
 	//  initialize flags
 	command string
 	service string
-)
 
-type ExecResult struct {
-	StdOut   string
-	StdErr   string
-	ExitCode int
-}
+	//  keep stdin attached to the command
+	interactive bool
+
+	//  allocate a pseudo-TTY for the command
+	tty bool
+
+	//  environment variables, working directory and user of the command
+	execEnv     []string
+	execWorkdir string
+	execUser    string
+)
 
 //  executeCmd represents the execute command
 var executeCmd = &cobra.Command{
-	Use:        "execute",
+	Use:        "execute [-s service] -- <command> [args...]",
 	SuggestFor: []string{"logs"},
 	Short:      "Execute commands inside your Nhost containers",
 	Long: `Run shell commands directly inside your 
-already running Nhost service containers.`,
+already running Nhost service containers.
+
+Everything after '--' is passed to the command as it is.
+Exits with the exit code of the command.`,
+	Example: `  nhost execute -s postgres -it -- psql -U postgres
+  nhost execute -s hasura -e DEBUG=true -- env`,
 	Run: func(cmd *cobra.Command, args []string) {
 
-		if command == "" {
+		//  --command is kept for backwards compatibility
+		if len(args) == 0 && command != "" {
+			parsed, err := splitCommand(command)
+			if err != nil {
+				status.Fatal(err.Error())
+			}
+			args = parsed
+		}
+
+		if len(args) == 0 {
 			status.Errorln("Invalid arguments")
 			status.Info("Run `nhost execute --help` to understand how to use this command")
 			os.Exit(0)
@@ -129,77 +149,162 @@ already running Nhost service containers.`,
 			log.Fatal("No such service found")
 		}
 
-		//  create the command execution skeleton
-		response, err := selected.Exec(env.Docker, env.Context, strings.Split(command, " "))
-		if err != nil {
-			log.WithField("service", service).Debug(err)
-			log.WithField("service", service).Fatal("Failed to prepare execution shell")
+		var stdin io.Reader
+		if interactive {
+			stdin = os.Stdin
 		}
 
-		//  execute the command
-		//  and inspect the response
-		result, err := InspectExecResp(env.Docker, env.Context, response.ID)
+		code, err := execute(selected, args, stdin)
 		if err != nil {
 			log.WithField("service", service).Debug(err)
-			log.WithField("service", service).Error("Failed to execute the command.")
-
-			if len(result.StdErr) > 0 {
-				os.Stderr.Write([]byte(result.StdErr))
-			}
+			log.WithField("service", service).Fatal("Failed to execute the command")
 		}
 
-		os.Stdout.Write([]byte(result.StdOut))
+		os.Exit(code)
 	},
 }
 
-func InspectExecResp(docker *client.Client, ctx context.Context, id string) (ExecResult, error) {
-	var execResult ExecResult
-
-	resp, err := docker.ContainerExecAttach(ctx, id, types.ExecStartCheck{})
+//  Runs the command inside service's container,
+//  attached to the current terminal, and returns it's exit code.
+//  Stdin of the command is only attached, if an input is given.
+func execute(service *nhost.Service, command []string, stdin io.Reader) (int, error) {
+
+	ctx := env.Context
+
+	response, err := env.Docker.ContainerExecCreate(ctx, service.ID, types.ExecConfig{
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          tty,
+		Env:          execEnv,
+		WorkingDir:   execWorkdir,
+		User:         execUser,
+		Cmd:          command,
+	})
 	if err != nil {
-		return execResult, err
+		return 0, err
 	}
-	defer resp.Close()
-
-	//  read the output
-	var outBuf, errBuf bytes.Buffer
-	outputDone := make(chan error)
 
-	go func() {
-		//  StdCopy demultiplexes the stream into two buffers
-		_, err = stdcopy.StdCopy(&outBuf, &errBuf, resp.Reader)
-		outputDone <- err
-	}()
+	attached, err := env.Docker.ContainerExecAttach(ctx, response.ID, types.ExecStartCheck{Tty: tty})
+	if err != nil {
+		return 0, err
+	}
+	defer attached.Close()
 
-	select {
-	case err := <-outputDone:
+	//  Pass the keystrokes to the command as they are,
+	//  including Ctrl+C, the way a local terminal does
+	if tty && stdin == os.Stdin && term.IsTerminal(int(os.Stdin.Fd())) {
+		state, err := term.MakeRaw(int(os.Stdin.Fd()))
 		if err != nil {
-			return execResult, err
+			return 0, err
 		}
-		break
+		defer term.Restore(int(os.Stdin.Fd()), state)
+	}
 
-	case <-ctx.Done():
-		return execResult, ctx.Err()
+	if tty && term.IsTerminal(int(os.Stdout.Fd())) {
+		go resizeExec(ctx, response.ID)
 	}
 
-	stdout, err := ioutil.ReadAll(&outBuf)
-	if err != nil {
-		return execResult, err
+	if stdin != nil {
+		go func() {
+			io.Copy(attached.Conn, stdin)
+			attached.CloseWrite()
+		}()
+	}
+
+	//  Output of commands without a TTY is multiplexed,
+	//  with separate frames for stdout and stderr
+	if tty {
+		_, err = io.Copy(os.Stdout, attached.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(os.Stdout, os.Stderr, attached.Reader)
 	}
-	stderr, err := ioutil.ReadAll(&errBuf)
 	if err != nil {
-		return execResult, err
+		return 0, err
 	}
 
-	res, err := docker.ContainerExecInspect(ctx, id)
+	result, err := env.Docker.ContainerExecInspect(ctx, response.ID)
 	if err != nil {
-		return execResult, err
+		return 0, err
+	}
+
+	return result.ExitCode, nil
+}
+
+//  Keeps the TTY of the command as large as the current terminal
+func resizeExec(ctx context.Context, id string) {
+
+	resized := make(chan os.Signal, 1)
+	util.NotifyResize(resized)
+
+	for {
+		if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			if err := env.Docker.ContainerExecResize(ctx, id, types.ResizeOptions{
+				Width:  uint(width),
+				Height: uint(height),
+			}); err != nil {
+				log.Debug(err)
+			}
+		}
+
+		select {
+		case <-resized:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+//  Splits the command into arguments, like a shell,
+//  keeping quoted arguments together.
+func splitCommand(command string) ([]string, error) {
+
+	var (
+		response []string
+		current  strings.Builder
+		quote    rune
+		escaped  bool
+		started  bool
+	)
+
+	for _, char := range command {
+		switch {
+		case escaped:
+			current.WriteRune(char)
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped = true
+			started = true
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case char == '"' || char == '\'':
+			quote = char
+			started = true
+		case char == ' ' || char == '\t' || char == '\n':
+			if started {
+				response = append(response, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(char)
+			started = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote in command: %s", command)
+	}
+
+	if started {
+		response = append(response, current.String())
 	}
 
-	execResult.ExitCode = res.ExitCode
-	execResult.StdOut = string(stdout)
-	execResult.StdErr = string(stderr)
-	return execResult, nil
+	return response, nil
 }
 
 func init() {
@@ -213,6 +318,11 @@ func init() {
 
 	//  Cobra supports local flags which will only run when this command
 	//  is called directly, e.g.:
-	executeCmd.Flags().StringVarP(&command, "command", "c", "", "Command to run inside service")
+	executeCmd.Flags().StringVarP(&command, "command", "c", "", "Command to run inside service, instead of the arguments after '--'")
 	executeCmd.Flags().StringVarP(&service, "service", "s", "", "Service to run the command inside")
+	executeCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Keep stdin attached to the command")
+	executeCmd.Flags().BoolVarP(&tty, "tty", "t", false, "Allocate a pseudo-TTY for the command")
+	executeCmd.Flags().StringArrayVarP(&execEnv, "env", "e", nil, "Environment variable of the command, like KEY=value")
+	executeCmd.Flags().StringVarP(&execWorkdir, "workdir", "w", "", "Working directory of the command inside the container")
+	executeCmd.Flags().StringVarP(&execUser, "user", "u", "", "User to run the command as, like postgres or 1000:1000")
 }
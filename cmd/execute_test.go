@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+
+	tests := []struct {
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{command: "psql -U postgres", want: []string{"psql", "-U", "postgres"}},
+		{command: `psql  -c "select * from users"`, want: []string{"psql", "-c", "select * from users"}},
+		{command: `echo 'it''s' a\ b ""`, want: []string{"echo", "its", "a b", ""}},
+		{command: `echo "unterminated`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitCommand(tt.command)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
@@ -27,6 +27,7 @@ package cmd
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -49,6 +50,9 @@ var (
 	functionServer *functions.Server
 
 	buildDir string
+
+	//  additional destination of the output of functions
+	functionsOutput io.Writer
 )
 
 //  uninstallCmd removed Nhost CLI from system
@@ -122,6 +126,7 @@ func ServeFuncs() error {
 		BuildDir:    buildDir,
 		Environment: &env,
 		Log:         log,
+		Output:      functionsOutput,
 	}
 
 	//	If the default/supplied port is available,
@@ -91,36 +91,7 @@ along with your original command.
 			adminsecret = util.ADMIN_SECRET
 
 		} else {
-
-			var app nhost.App
-
-			for {
-
-				//	Read the app info saved locally
-				var err error
-				app, err = nhost.Info()
-				if err != nil {
-					log.Debug(err)
-					status.Error("Failed to fetch app info locally")
-					status.Info("Please run `nhost link`")
-					os.Exit(0)
-				}
-
-				if app.ID == "" {
-
-					//	Run `nhost link`
-					linkCmd.PreRun(cmd, args)
-					linkCmd.Run(cmd, args)
-
-				} else {
-					break
-				}
-
-			}
-
-			//	Update the credentials
-			adminsecret = app.GraphQLAdminSecret
-			endpoint = fmt.Sprintf("https://%s.%s", app.Subdomain, nhost.DOMAIN)
+			endpoint, adminsecret = productionHasura(cmd, args)
 		}
 
 		status.Clean()
@@ -156,6 +127,39 @@ along with your original command.
 	},
 }
 
+//  Returns the Hasura endpoint and admin secret of the linked production app.
+//  Links the app first, if it isn't linked yet.
+func productionHasura(cmd *cobra.Command, args []string) (string, string) {
+
+	var app nhost.App
+
+	for {
+
+		//	Read the app info saved locally
+		var err error
+		app, err = nhost.Info()
+		if err != nil {
+			log.Debug(err)
+			status.Error("Failed to fetch app info locally")
+			status.Info("Please run `nhost link`")
+			os.Exit(0)
+		}
+
+		if app.ID == "" {
+
+			//	Run `nhost link`
+			linkCmd.PreRun(cmd, args)
+			linkCmd.Run(cmd, args)
+
+		} else {
+			break
+		}
+
+	}
+
+	return fmt.Sprintf("https://%s.%s", app.Subdomain, nhost.DOMAIN), app.GraphQLAdminSecret
+}
+
 func init() {
 	rootCmd.AddCommand(hasuraCmd)
 
@@ -26,6 +26,7 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -54,6 +55,9 @@ Without specifying --remote flag, only a blank Nhost app will be initialized.
 
 Specifying --remote flag will initialize a local app from console.nhost.io
 
+The remote app isn't modified, unless you use --force, which records
+the init migration as applied on it, since it already contains its changes.
+
 To bypass remote app selection prompt, add your remote app's subdomain after --remote flag,
 in the following manner:
 
@@ -240,19 +244,47 @@ in the following manner:
 			//	adminSecret := "hasura-admin-secret"
 
 			//  create new hasura client
-			hasuraClient := hasura.Client{}
-			if err := hasuraClient.Init(hasuraEndpoint, adminSecret, nil); err != nil {
-				log.Debug(err)
-				status.Fatal("Failed to initialize Hasura client")
+			hasuraClient := hasura.Client{
+				Endpoint:    hasuraEndpoint,
+				AdminSecret: adminSecret,
+				Client:      &http.Client{},
 			}
 
 			//  create migrations from remote
-			_, err := pullMigration(hasuraClient, "init")
+			migration, down, err := pullMigration(&hasuraClient, nil, "init")
 			if err != nil {
 				log.Debug(err)
 				status.Fatal("Failed to pull migrations from remote")
 			}
 
+			if len(migration.Data) > 0 {
+				if err := writeMigration(migration, down); err != nil {
+					log.Debug(err)
+					status.Fatal("Failed to write migration")
+				}
+
+				//  The remote app already contains it's changes,
+				//  and keeps the migrations it has applied before.
+				//  It's only modified with the user's consent.
+				if force {
+					if err := hasuraClient.MarkMigrations(nhost.DATABASE, map[int64]bool{migration.Version: true}); err != nil {
+						log.Debug(err)
+						status.Fatal("Failed to record the migration as applied on remote")
+					}
+				} else {
+					status.Warnln("Remote app won't record the init migration as applied, so deploying it would apply it again. Use `--force` to record it")
+				}
+			}
+
+			metadata, err := hasuraClient.FetchMetadata()
+			if err == nil {
+				err = metadata.Write(nhost.METADATA_DIR)
+			}
+			if err != nil {
+				log.Debug(err)
+				status.Fatal("Failed to export metadata from remote")
+			}
+
 			//  write ENV variables to .env.development
 			var envArray []string
 			for _, row := range selectedProject.EnvVars {
@@ -397,6 +429,7 @@ func init() {
 	initCmd.Flags().StringVarP(&name, "name", "n", "", "Name of new app")
 	initCmd.Flags().BoolVarP(&remote, "remote", "r", false, "Initialize app from remote?")
 	initCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass app initialization prompt")
+	initCmd.Flags().BoolVar(&force, "force", false, "Record the init migration as applied on remote")
 
 	//  Cobra supports local flags which will only run when this command
 	//  is called directly, e.g.:
@@ -25,20 +25,60 @@ SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/manifoldco/promptui"
 	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
-//  logsCmd prints the logs from containers and HBP_Catalog
+var (
+
+	//  keep printing new logs
+	followLogs bool
+
+	//  number of last lines to print of every service
+	tailLogs int
+
+	//  only print logs newer than this
+	sinceLogs string
+
+	//  print the timestamp of every line
+	logTimestamps bool
+
+	//  colours of service prefixes
+	logColours = []string{util.Cyan, util.Yellow, util.Green, util.Blue}
+
+	//  log files of processes running outside containers
+	logFiles []io.Closer
+)
+
+//  Reads logs of a single service
+type logSource func(ctx context.Context, options nhost.LogOptions, emit func(nhost.LogLine)) error
+
+//  logsCmd prints the logs from containers, functions and proxy
 var logsCmd = &cobra.Command{
-	Use:        "logs",
+	Use:        "logs [service...]",
 	Aliases:    []string{"log"},
 	SuggestFor: []string{"execute"},
-	Short:      "Read container logs of any service",
+	Short:      "Read logs of your app's services",
+	Long: `Print the logs of your app's services, including stderr.
+
+Logs of all services, functions and the proxy are printed,
+merged in order of time, unless you choose some of them.`,
+	Example: `  nhost logs -f
+  nhost logs hasura auth --since 10m
+  nhost logs postgres --tail 50 --timestamps`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 
 		//  Initialize the runtime environment
@@ -54,70 +94,290 @@ var logsCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 
-		var selected *nhost.Service
-
-		if service == "" {
+		options := nhost.LogOptions{Tail: tailLogs}
 
-			//  load the saved Nhost configuration
-			type Option struct {
-				Key   string
-				Value string
+		if sinceLogs != "" {
+			since, err := parseSince(sinceLogs)
+			if err != nil {
+				status.Fatal(err.Error())
 			}
+			options.Since = since
+		}
 
-			var services []Option
-			for name := range env.Config.Services {
-				services = append(services, Option{
-					Key:   strings.Title(strings.ToLower(name)),
-					Value: name,
-				})
-			}
+		if service != "" {
+			args = append(args, service)
+		}
+
+		sources, err := selectLogSources(logSources(), args)
+		if err != nil {
+			status.Fatal(err.Error())
+		}
+
+		ctx, cancel := context.WithCancel(env.Context)
+		defer cancel()
+
+		//  stop following the logs on interruption
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			cancel()
+		}()
+
+		printer := newLogPrinter(sources)
+
+		//  Print the existing logs of all services, in order of time
+		started := time.Now()
+		lines := readLogs(ctx, sources, options)
 
-			//  configure interactive prompt template
-			templates := promptui.SelectTemplates{
-				Active:   `{{ "✔" | green | bold }} {{ .Key | cyan | bold }}`,
-				Inactive: `   {{ .Key | cyan }}`,
-				Selected: `{{ "✔" | green | bold }} {{ "Selected" | bold }}: {{ .Key | cyan }}`,
+		sort.SliceStable(lines, func(i, j int) bool {
+			return lines[i].Time.Before(lines[j].Time)
+		})
+
+		//  Time of the last printed line of every service,
+		//  and how many times every text has been printed at that time
+		last := make(map[string]time.Time)
+		printed := make(map[string]map[string]int)
+		for _, line := range lines {
+			printer.print(line)
+
+			if !line.Time.Equal(last[line.Service]) {
+				last[line.Service] = line.Time
+				printed[line.Service] = make(map[string]int)
 			}
+			printed[line.Service][line.Text]++
+		}
+
+		if !followLogs {
+			return
+		}
+
+		var waiter sync.WaitGroup
+		for name, source := range sources {
 
-			//  configure interative prompt
-			prompt := promptui.Select{
-				Label:     "Select Service",
-				Items:     services,
-				Templates: &templates,
+			//  Continue from the time of the last printed line,
+			//  without printing the lines of that time again.
+			//  Other lines of the same time are still printed.
+			since, ok := last[name]
+			if !ok {
+				since = started
 			}
 
-			index, _, err := prompt.Run()
-			if err != nil {
-				os.Exit(0)
+			waiter.Add(1)
+			go func(name string, source logSource, since time.Time, printed map[string]int) {
+				defer waiter.Done()
+
+				options := nhost.LogOptions{Follow: true, Tail: -1, Since: since}
+				if err := source(ctx, options, func(line nhost.LogLine) {
+					if line.Time.Before(since) {
+						return
+					}
+
+					if line.Time.Equal(since) && printed[line.Text] > 0 {
+						printed[line.Text]--
+						return
+					}
+
+					printer.print(line)
+				}); err != nil && ctx.Err() == nil {
+					log.WithField("component", name).Debug(err)
+					status.Errorln(fmt.Sprintf("Failed to follow logs of %s", name))
+				}
+			}(name, source, since, printed[name])
+		}
+
+		waiter.Wait()
+	},
+}
+
+//  Returns log sources of all running containers,
+//  and of functions and proxy, if they have logged anything.
+func logSources() map[string]logSource {
+
+	response := make(map[string]logSource)
+
+	for name, item := range env.Config.Services {
+
+		//  Skip the services without a container
+		if item.ID == "" {
+			continue
+		}
+
+		service := item
+		response[name] = func(ctx context.Context, options nhost.LogOptions, emit func(nhost.LogLine)) error {
+			return service.Logs(env.Docker, ctx, options, emit)
+		}
+	}
+
+	for _, name := range []string{"functions", "proxy"} {
+		if !util.PathExists(nhost.LogPath(name)) {
+			continue
+		}
+
+		name := name
+		response[name] = func(ctx context.Context, options nhost.LogOptions, emit func(nhost.LogLine)) error {
+			return nhost.ReadLog(ctx, name, options, emit)
+		}
+	}
+
+	return response
+}
+
+//  Filters the log sources by service names.
+//  Returns all of them if no names are given.
+func selectLogSources(sources map[string]logSource, names []string) (map[string]logSource, error) {
+
+	if len(names) == 0 {
+		return sources, nil
+	}
+
+	response := make(map[string]logSource)
+	for _, name := range names {
+
+		var found bool
+		for key, source := range sources {
+			if strings.EqualFold(key, name) {
+				response[key] = source
+				found = true
 			}
+		}
 
-			service = services[index].Value
+		if !found {
+			return response, fmt.Errorf("no logs found for service %s", name)
 		}
+	}
 
-		for name, item := range env.Config.Services {
-			if strings.EqualFold(name, service) {
-				selected = item
-				break
+	return response, nil
+}
+
+//  Reads the logs of all sources concurrently
+func readLogs(ctx context.Context, sources map[string]logSource, options nhost.LogOptions) []nhost.LogLine {
+
+	var (
+		response []nhost.LogLine
+		mutex    sync.Mutex
+		waiter   sync.WaitGroup
+	)
+
+	for name, source := range sources {
+		waiter.Add(1)
+		go func(name string, source logSource) {
+			defer waiter.Done()
+
+			if err := source(ctx, options, func(line nhost.LogLine) {
+				mutex.Lock()
+				response = append(response, line)
+				mutex.Unlock()
+			}); err != nil && ctx.Err() == nil {
+				log.WithField("component", name).Debug(err)
+				status.Errorln(fmt.Sprintf("Failed to fetch logs of %s", name))
 			}
+		}(name, source)
+	}
+
+	waiter.Wait()
+	return response
+}
+
+//  Parses a duration relative to now, like 10m,
+//  or an absolute time, like 2021-11-01T15:04:05Z or 2021-11-01
+func parseSince(value string) (time.Time, error) {
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if since, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return since, nil
 		}
+	}
 
-		if selected == nil {
-			log.Fatal("No such service found")
+	return time.Time{}, fmt.Errorf("invalid time %q, use a duration like 10m, or a time like 2006-01-02T15:04:05Z", value)
+}
+
+//  Prints log lines prefixed with their service
+type logPrinter struct {
+	prefixes map[string]string
+	out      io.Writer
+	mutex    sync.Mutex
+}
+
+func newLogPrinter(sources map[string]logSource) *logPrinter {
+
+	var names []string
+	width := 0
+	for name := range sources {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
 		}
+	}
+	sort.Strings(names)
 
-		//  fetch the logs of selected container
-		logs, err := selected.Logs(env.Docker, env.Context)
-		if err != nil {
-			log.WithField("component", selected.Name).Debug(err)
-			log.WithField("component", selected.Name).Fatal("Failed to fetch service logs")
+	//  Only colour the output for terminals
+	colour := term.IsTerminal(int(os.Stdout.Fd()))
+
+	response := &logPrinter{
+		prefixes: make(map[string]string),
+		out:      os.Stdout,
+	}
+
+	for index, name := range names {
+		prefix := fmt.Sprintf("%-*s |", width, name)
+		if colour {
+			prefix = logColours[index%len(logColours)] + prefix + util.Reset
 		}
+		response.prefixes[name] = prefix
+	}
 
-		//	print the logs for the user
-		os.Stdout.Write(logs)
-	},
+	return response
+}
+
+func (p *logPrinter) print(line nhost.LogLine) {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if logTimestamps {
+		fmt.Fprintf(p.out, "%s %s %s\n", p.prefixes[line.Service], line.Time.Local().Format(time.RFC3339Nano), line.Text)
+		return
+	}
+
+	fmt.Fprintf(p.out, "%s %s\n", p.prefixes[line.Service], line.Text)
+}
+
+//  Creates the log file of a process running outside containers,
+//  to be read by `nhost logs`. Returns nil, if it can't be created.
+//  It's closed by closeLogs.
+func createLog(name string) io.Writer {
+
+	file, err := nhost.CreateLog(name)
+	if err != nil {
+		log.WithField("component", name).Debug(err)
+		return nil
+	}
+
+	logFiles = append(logFiles, file)
+	return file
+}
+
+//  Closes the log files, writing their last lines,
+//  which may not have ended with a newline yet
+func closeLogs() {
+	for _, item := range logFiles {
+		if err := item.Close(); err != nil {
+			log.Debug(err)
+		}
+	}
+	logFiles = nil
 }
 
 func init() {
 	rootCmd.AddCommand(logsCmd)
 	logsCmd.Flags().StringVarP(&service, "service", "s", "", "Service to fetch the logs for")
+	logsCmd.Flags().BoolVarP(&followLogs, "follow", "f", false, "Keep printing new logs")
+	logsCmd.Flags().IntVarP(&tailLogs, "tail", "n", -1, "Number of last lines to print of every service, all of them if negative")
+	logsCmd.Flags().StringVar(&sinceLogs, "since", "", "Only print logs newer than a duration, like 10m, or a time, like 2006-01-02T15:04:05Z")
+	logsCmd.Flags().BoolVarP(&logTimestamps, "timestamps", "t", false, "Print the timestamp of every line")
 }
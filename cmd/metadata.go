@@ -0,0 +1,381 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/nhost/cli/hasura"
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+
+	//  apply metadata even if some of it's objects are inconsistent
+	allowInconsistent bool
+
+	//  drop inconsistent objects from the metadata
+	dropInconsistent bool
+)
+
+//  metadataCmd manages the Hasura metadata of the app
+var metadataCmd = &cobra.Command{
+	Use:     "metadata",
+	Aliases: []string{"md"},
+	Short:   "Manage the Hasura metadata of your app",
+	Long: `Compare, apply, reload and export the metadata in nhost/metadata,
+directly through Hasura's metadata API of your running app.`,
+}
+
+//  metadataDiffCmd compares local metadata with the server's
+var metadataDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare local metadata with the server",
+	Long: `Show the changes which applying the metadata in nhost/metadata
+would make to the metadata of your running app, or with --prod,
+to the metadata of your linked production app.`,
+	Example: `  nhost metadata diff
+  nhost metadata diff --prod`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		local, err := hasura.LoadMetadata(nhost.METADATA_DIR)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to load local metadata: %v", err))
+		}
+
+		var client *hasura.Client
+		name := "server"
+
+		if production {
+			client = &hasura.Client{Client: &http.Client{}}
+			client.Endpoint, client.AdminSecret = productionHasura(cmd, args)
+			name = "production"
+		} else {
+			client = localHasuraClient()
+		}
+
+		remote, err := client.FetchMetadata()
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to export metadata of the %s: %v", name, err))
+		}
+
+		diffs, err := hasura.DiffMetadata(remote, local, name, "local")
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to compare metadata")
+		}
+
+		if len(diffs) == 0 {
+			status.Successln(fmt.Sprintf("Local metadata is in sync with the %s", name))
+			return
+		}
+
+		for _, item := range diffs {
+			printDiff(item.Diff)
+		}
+
+		fmt.Println()
+		status.Infoln(fmt.Sprintf("%d metadata files differ from the %s", len(diffs), name))
+	},
+}
+
+//  metadataApplyCmd replaces the server's metadata with local metadata
+var metadataApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply local metadata to your running app",
+	Long: `Replace the metadata of your running app with the metadata in nhost/metadata.
+
+If any object of it is inconsistent, like a table which doesn't exist,
+nothing is applied, unless you use --allow-inconsistent.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		metadata, err := hasura.LoadMetadata(nhost.METADATA_DIR)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to load local metadata: %v", err))
+		}
+
+		client := localHasuraClient()
+
+		response, err := client.ApplyMetadata(metadata, allowInconsistent)
+		if err != nil {
+			var failure *hasura.MetadataError
+			if errors.As(err, &failure) && len(failure.Inconsistencies) > 0 {
+				status.Errorln("Metadata is inconsistent, nothing was applied")
+				printInconsistencies(failure.Inconsistencies)
+				status.Fatal("Fix the inconsistent objects, or apply them anyway with `--allow-inconsistent`")
+			}
+
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to apply metadata: %v", err))
+		}
+
+		if !response.IsConsistent {
+			status.Warnln("Metadata applied, with inconsistent objects")
+			printInconsistencies(response.InconsistentObjects)
+			return
+		}
+
+		status.Successln("Metadata applied")
+	},
+}
+
+//  metadataReloadCmd reloads the server's metadata
+var metadataReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload the metadata of your running app",
+	Long: `Reload the metadata of your running app, to pick up changes
+made to the database, or remote schemas, outside of Hasura.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		client := localHasuraClient()
+
+		if _, err := client.ReloadMetadata(); err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to reload metadata: %v", err))
+		}
+
+		response, err := client.GetInconsistentMetadata()
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to fetch inconsistent metadata")
+		}
+
+		if !response.IsConsistent {
+			status.Warnln("Metadata reloaded, with inconsistent objects")
+			printInconsistencies(response.InconsistentObjects)
+			return
+		}
+
+		status.Successln("Metadata reloaded")
+	},
+}
+
+//  metadataExportCmd saves the server's metadata to local files
+var metadataExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export metadata of your running app to local files",
+	Long: `Replace the contents of nhost/metadata
+with the metadata of your running app.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		metadata, err := localHasuraClient().FetchMetadata()
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to export metadata: %v", err))
+		}
+
+		if err := metadata.Write(nhost.METADATA_DIR); err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to save metadata to %s", util.Rel(nhost.METADATA_DIR)))
+		}
+
+		status.Successln(fmt.Sprintf("Metadata exported to %s", util.Rel(nhost.METADATA_DIR)))
+	},
+}
+
+//  metadataInconsistenciesCmd lists inconsistent objects of the server's metadata
+var metadataInconsistenciesCmd = &cobra.Command{
+	Use:     "inconsistencies",
+	Aliases: []string{"ic"},
+	Short:   "List inconsistent objects of the metadata",
+	Long: `List the objects of the metadata of your running app,
+which Hasura failed to apply, like relationships of dropped tables.
+
+Remove them from the metadata with --drop.`,
+	Example: `  nhost metadata inconsistencies
+  nhost metadata inconsistencies --drop`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		client := localHasuraClient()
+
+		response, err := client.GetInconsistentMetadata()
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to fetch inconsistent metadata: %v", err))
+		}
+
+		if response.IsConsistent {
+			status.Successln("Metadata is consistent")
+			return
+		}
+
+		printInconsistencies(response.InconsistentObjects)
+
+		if !dropInconsistent {
+			return
+		}
+
+		//  if the user has not pre-approved dropping the objects,
+		//  take the user's approval manually
+		if !approve {
+			prompt := promptui.Prompt{
+				Label:     "Drop these objects from the metadata",
+				IsConfirm: true,
+			}
+
+			if _, err := prompt.Run(); err != nil {
+				os.Exit(0)
+			}
+		}
+
+		if _, err := client.DropInconsistentMetadata(); err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to drop inconsistent metadata: %v", err))
+		}
+
+		status.Successln("Dropped inconsistent objects. Run `nhost metadata export` to remove them from local metadata too")
+	},
+}
+
+//  Returns a client of Hasura's APIs of the running app,
+//  which doesn't need the Hasura CLI
+func localHasuraClient() *hasura.Client {
+	return &hasura.Client{
+		Endpoint:    localHasura(),
+		AdminSecret: util.ADMIN_SECRET,
+		Client:      &http.Client{},
+	}
+}
+
+//  Prints a unified diff, coloured for terminals
+func printDiff(diff string) {
+
+	colour := term.IsTerminal(int(os.Stdout.Fd()))
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if colour {
+			switch {
+			case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+				line = util.Bold + line + util.Reset
+			case strings.HasPrefix(line, "@@"):
+				line = util.Cyan + line + util.Reset
+			case strings.HasPrefix(line, "+"):
+				line = util.Green + line + util.Reset
+			case strings.HasPrefix(line, "-"):
+				line = util.Red + line + util.Reset
+			}
+		}
+		fmt.Print(line)
+	}
+}
+
+//  Prints the inconsistent objects, grouped by their types
+func printInconsistencies(objects []hasura.InconsistentObject) {
+	fmt.Println(hasura.InconsistencyReport(objects))
+}
+
+//  Offers to drop the inconsistent objects, which were skipped by `nhost dev`,
+//  or to open the files defining them. Only prompts in terminals.
+func resolveInconsistencies() {
+
+	if len(env.Inconsistencies) == 0 || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+
+	var files []string
+	for _, item := range env.Inconsistencies {
+		if file := item.File(); file != "" && !util.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	items := []string{"Continue without them", "Drop them from the metadata, and nhost/metadata"}
+	for _, file := range files {
+		items = append(items, "Open "+util.Rel(filepath.Join(nhost.METADATA_DIR, file)))
+	}
+
+	for {
+		prompt := promptui.Select{
+			Label: "Your app is running without the inconsistent objects",
+			Items: items,
+		}
+
+		index, _, err := prompt.Run()
+		if err != nil || index == 0 {
+			return
+		}
+
+		if index > 1 {
+			if err := openbrowser(filepath.Join(nhost.METADATA_DIR, files[index-2])); err != nil {
+				log.Debug(err)
+				status.Errorln("Failed to open the file")
+			}
+			continue
+		}
+
+		if _, err := env.Hasura.DropInconsistentMetadata(); err != nil {
+			log.Debug(err)
+			status.Errorln(fmt.Sprintf("Failed to drop inconsistent metadata: %v", err))
+			return
+		}
+
+		//  Keep local metadata in sync, so they aren't applied again
+		metadata, err := env.Hasura.FetchMetadata()
+		if err == nil {
+			err = metadata.Write(nhost.METADATA_DIR)
+		}
+		if err != nil {
+			log.Debug(err)
+			status.Errorln("Dropped inconsistent objects, but failed to remove them from local metadata. Run `nhost metadata export`")
+			return
+		}
+
+		env.Inconsistencies = nil
+		status.Successln("Dropped inconsistent objects")
+		return
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(metadataCmd)
+	metadataCmd.AddCommand(metadataDiffCmd)
+	metadataCmd.AddCommand(metadataApplyCmd)
+	metadataCmd.AddCommand(metadataReloadCmd)
+	metadataCmd.AddCommand(metadataExportCmd)
+	metadataCmd.AddCommand(metadataInconsistenciesCmd)
+
+	metadataDiffCmd.Flags().BoolVar(&production, "prod", false, "Compare with the metadata of the linked production app")
+	metadataApplyCmd.Flags().BoolVar(&allowInconsistent, "allow-inconsistent", false, "Apply the metadata, even if some objects are inconsistent")
+	metadataInconsistenciesCmd.Flags().BoolVar(&dropInconsistent, "drop", false, "Drop the inconsistent objects from the metadata")
+	metadataInconsistenciesCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass the confirmation prompt")
+}
@@ -0,0 +1,430 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/manifoldco/promptui"
+	"github.com/nhost/cli/hasura"
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+
+	//  migration version to roll back, or squash from
+	migrationVersion string
+
+	//  number of migrations to roll back
+	migrationSteps int
+
+	//  name of the squashed migration
+	migrationName string
+)
+
+//  migrateCmd manages the migrations of the database
+var migrateCmd = &cobra.Command{
+	Use:     "migrate",
+	Aliases: []string{"migrations"},
+	Short:   "Manage the migrations of your database",
+	Long: `Create, inspect, roll back and squash the migrations
+in nhost/migrations/default, against the running app
+of your current git branch.`,
+}
+
+//  migrateCreateCmd creates a new migration skeleton
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new migration",
+	Long: `Create a new migration directory in nhost/migrations/default,
+with empty up.sql and down.sql files, to be applied after existing ones.`,
+	Example: `  nhost migrate create add_todos`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if !regexp.MustCompile(`^[A-Za-z0-9_-]+$`).MatchString(args[0]) {
+			status.Fatal(fmt.Sprintf("Invalid migration name %q, only letters, digits, '_' and '-' are allowed", args[0]))
+		}
+
+		migration := (&hasura.Migration{Name: args[0]}).Init(nhost.DATABASE)
+
+		if err := os.MkdirAll(migration.Location, os.ModePerm); err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to create migration directory")
+		}
+
+		for file, content := range map[string]string{
+			"up.sql":   fmt.Sprintf("-- Migration: %s\n", args[0]),
+			"down.sql": fmt.Sprintf("-- Revert migration: %s\n", args[0]),
+		} {
+			if err := ioutil.WriteFile(filepath.Join(migration.Location, file), []byte(content), 0644); err != nil {
+				log.Debug(err)
+				status.Fatal("Failed to create migration")
+			}
+		}
+
+		status.Successln(fmt.Sprintf("Created migration %s", util.Rel(migration.Location)))
+	},
+}
+
+//  migrateStatusCmd compares local migrations with the applied ones
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of migrations",
+	Long: `List local migrations, and whether they have been applied
+to the database of your running app, or with --prod,
+to the database of your linked production app.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		var client *hasura.Client
+		if production {
+			client = &hasura.Client{Client: &http.Client{}}
+			client.Endpoint, client.AdminSecret = productionHasura(cmd, args)
+		} else {
+			client = localHasuraClient()
+		}
+
+		migrations, err := hasura.LocalMigrations(nhost.DATABASE)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to read local migrations")
+		}
+
+		applied, err := client.AppliedMigrations(nhost.DATABASE)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to fetch the status of migrations")
+		}
+
+		fmt.Print(migrationStatus(migrations, applied))
+	},
+}
+
+//  migrateDownCmd rolls back applied migrations
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Roll back migrations",
+	Long: `Roll back the last applied migrations of your local database,
+by applying their down.sql files.
+
+Either roll back a number of migrations with --steps,
+or a specific one with --version.`,
+	Example: `  nhost migrate down --steps 1
+  nhost migrate down --version 1637000000000`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if migrationVersion != "" && migrationSteps > 0 {
+			status.Fatal("Mention either `--version` or `--steps`, not both")
+		}
+
+		if migrationVersion == "" && migrationSteps <= 0 {
+			status.Fatal("Mention the migrations to roll back with `--version` or `--steps`")
+		}
+
+		migrations, err := hasura.LocalMigrations(nhost.DATABASE)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to read local migrations")
+		}
+
+		if migrationVersion != "" && !migrationVersions(migrations)[migrationVersion] {
+			status.Fatal(fmt.Sprintf("Migration %s doesn't exist in %s", migrationVersion, util.Rel(filepath.Join(nhost.MIGRATIONS_DIR, nhost.DATABASE))))
+		}
+
+		client := localHasuraClient()
+
+		applied, err := client.AppliedMigrations(nhost.DATABASE)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to fetch the status of migrations")
+		}
+
+		rollback := migrationsToRollback(migrations, applied, migrationVersion, migrationSteps)
+		if len(rollback) == 0 {
+			status.Infoln("No applied migrations to roll back")
+			return
+		}
+
+		//  if the user has not pre-approved the rollback,
+		//  take the user's approval manually
+		if !approve {
+			status.Warnln("Rolling back migrations may delete data. Save it first with `nhost data snapshot`, to undo this")
+
+			prompt := promptui.Prompt{
+				Label:     "Are you sure you want to continue",
+				IsConfirm: true,
+			}
+
+			if _, err := prompt.Run(); err != nil {
+				os.Exit(0)
+			}
+		}
+
+		for _, item := range rollback {
+			if err := client.RollbackMigration(nhost.DATABASE, item); err != nil {
+				log.Debug(err)
+				status.Fatal(fmt.Sprintf("Failed to roll back migrations: %v", err))
+			}
+
+			status.Successln(fmt.Sprintf("Rolled back %s", filepath.Base(item.Location)))
+		}
+	},
+}
+
+//  migrateSquashCmd squashes migrations into a single one
+var migrateSquashCmd = &cobra.Command{
+	Use:   "squash --from <version>",
+	Short: "Squash migrations into a single one",
+	Long: `Squash all migrations, starting from a version,
+into a single new migration, and mark it as applied
+on the database of your running app.
+
+The squashed migrations are deleted, and forgotten by the database,
+so that they aren't applied again along with the squashed one.`,
+	Example: `  nhost migrate squash --from 1637000000000 --name initial`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		if migrationVersion == "" {
+			status.Fatal("Mention the version to squash from with `--from`")
+		}
+
+		migrations, err := hasura.LocalMigrations(nhost.DATABASE)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to read local migrations")
+		}
+
+		if !migrationVersions(migrations)[migrationVersion] {
+			status.Fatal(fmt.Sprintf("Migration %s doesn't exist in %s", migrationVersion, util.Rel(filepath.Join(nhost.MIGRATIONS_DIR, nhost.DATABASE))))
+		}
+
+		client := localHasuraClient()
+
+		applied, err := client.AppliedMigrations(nhost.DATABASE)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to fetch the status of migrations")
+		}
+
+		from, _ := strconv.ParseInt(migrationVersion, 10, 64)
+
+		var squashed []hasura.Migration
+		for _, item := range migrations {
+			if item.Version < from {
+				continue
+			}
+
+			//  The squashed migration is marked as applied,
+			//  so all of it's changes must be in the database already
+			if !applied[item.Version] {
+				status.Fatal(fmt.Sprintf("Migration %s hasn't been applied yet, apply it with `nhost dev` first", filepath.Base(item.Location)))
+			}
+
+			squashed = append(squashed, item)
+		}
+
+		up, down, err := squashMigrations(squashed)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to read the migrations to squash")
+		}
+
+		migration := (&hasura.Migration{Name: migrationName}).Init(nhost.DATABASE)
+
+		if err := os.MkdirAll(migration.Location, os.ModePerm); err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to create migration directory")
+		}
+
+		for file, content := range map[string]string{"up.sql": up, "down.sql": down} {
+			if err := ioutil.WriteFile(filepath.Join(migration.Location, file), []byte(content), 0644); err != nil {
+				log.Debug(err)
+				status.Fatal("Failed to create squashed migration")
+			}
+		}
+
+		//  Its changes are in the database already, and the squashed ones are forgotten,
+		//  in a single transaction, before anything is deleted.
+		//  If that fails, the new migration is removed, and the squashed ones are kept.
+		versions := map[int64]bool{migration.Version: true}
+		for _, item := range squashed {
+			versions[item.Version] = false
+		}
+
+		if err := client.MarkMigrations(nhost.DATABASE, versions); err != nil {
+			log.Debug(err)
+			os.RemoveAll(migration.Location)
+			status.Fatal(fmt.Sprintf("Failed to mark migration %d as applied", migration.Version))
+		}
+
+		for _, item := range squashed {
+			if err := os.RemoveAll(item.Location); err != nil {
+				log.Debug(err)
+				status.Fatal(fmt.Sprintf("Failed to delete %s, delete it manually, it has already been squashed", util.Rel(item.Location)))
+			}
+		}
+
+		status.Successln(fmt.Sprintf("Squashed %d migrations into %s", len(squashed), util.Rel(migration.Location)))
+	},
+}
+
+//  Prints local migrations, and those applied to the database,
+//  in the order of their versions
+func migrationStatus(migrations []hasura.Migration, applied map[int64]bool) string {
+
+	names := make(map[int64]string)
+	for _, item := range migrations {
+		names[item.Version] = item.Name
+	}
+
+	var versions []int64
+	for version := range names {
+		versions = append(versions, version)
+	}
+	for version := range applied {
+		if _, ok := names[version]; !ok {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	var response bytes.Buffer
+	writer := tabwriter.NewWriter(&response, 1, 1, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tLOCAL\tDATABASE")
+
+	for _, version := range versions {
+
+		local, database := "Present", "Applied"
+
+		name, ok := names[version]
+		if !ok {
+			name, local = "-", "Not Present"
+		}
+		if !applied[version] {
+			database = "Not Applied"
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", version, name, local, database)
+	}
+	writer.Flush()
+
+	return response.String()
+}
+
+//  Returns the applied migrations to roll back, latest first.
+//  Either the one of the version, or the number of last ones.
+func migrationsToRollback(migrations []hasura.Migration, applied map[int64]bool, version string, steps int) []hasura.Migration {
+
+	var response []hasura.Migration
+
+	for index := len(migrations) - 1; index >= 0; index-- {
+
+		item := migrations[index]
+		if !applied[item.Version] {
+			continue
+		}
+
+		if version != "" {
+			if fmt.Sprint(item.Version) == version {
+				return []hasura.Migration{item}
+			}
+			continue
+		}
+
+		if len(response) == steps {
+			break
+		}
+		response = append(response, item)
+	}
+
+	return response
+}
+
+//  Concatenates the up.sql files of the migrations in their order,
+//  and their down.sql files in the reverse one
+func squashMigrations(migrations []hasura.Migration) (string, string, error) {
+
+	var up, down []string
+
+	for index := range migrations {
+
+		sql, err := migrations[index].SQL("up.sql")
+		if err != nil {
+			return "", "", err
+		}
+		up = append(up, fmt.Sprintf("-- %s\n%s", filepath.Base(migrations[index].Location), strings.TrimSpace(sql)))
+	}
+
+	for index := len(migrations) - 1; index >= 0; index-- {
+
+		sql, err := migrations[index].SQL("down.sql")
+		if err != nil {
+			return "", "", err
+		}
+		down = append(down, fmt.Sprintf("-- %s\n%s", filepath.Base(migrations[index].Location), strings.TrimSpace(sql)))
+	}
+
+	return strings.Join(up, "\n\n") + "\n", strings.Join(down, "\n\n") + "\n", nil
+}
+
+//  Returns the versions of the migrations, as mentioned in flags
+func migrationVersions(migrations []hasura.Migration) map[string]bool {
+
+	response := make(map[string]bool)
+	for _, item := range migrations {
+		response[fmt.Sprint(item.Version)] = true
+	}
+
+	return response
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateCreateCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateSquashCmd)
+
+	migrateStatusCmd.Flags().BoolVar(&production, "prod", false, "Show the status of the linked production app")
+	migrateDownCmd.Flags().StringVar(&migrationVersion, "version", "", "Version of the migration to roll back")
+	migrateDownCmd.Flags().IntVar(&migrationSteps, "steps", 0, "Number of last applied migrations to roll back")
+	migrateDownCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass the confirmation prompt")
+	migrateSquashCmd.Flags().StringVar(&migrationVersion, "from", "", "Version of the first migration to squash")
+	migrateSquashCmd.Flags().StringVar(&migrationName, "name", "squashed", "Name of the squashed migration")
+}
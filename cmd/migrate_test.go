@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nhost/cli/hasura"
+	"github.com/nhost/cli/nhost"
+)
+
+func TestMigrationVersions(t *testing.T) {
+
+	defer func(dir string) { nhost.MIGRATIONS_DIR = dir }(nhost.MIGRATIONS_DIR)
+	nhost.MIGRATIONS_DIR = t.TempDir()
+
+	//  Directories without a name aren't migrations, the same as when applying them
+	for _, name := range []string{"1637000000000_init", "1637000000001_add_todos", "1637000000002_", "README"} {
+		if err := os.MkdirAll(filepath.Join(nhost.MIGRATIONS_DIR, nhost.DATABASE, name), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrations, err := hasura.LocalMigrations(nhost.DATABASE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{"1637000000000": true, "1637000000001": true}
+	if got := migrationVersions(migrations); !reflect.DeepEqual(got, want) {
+		t.Errorf("migrationVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestMigrationsToRollback(t *testing.T) {
+
+	migrations := []hasura.Migration{{Version: 1, Name: "init"}, {Version: 2, Name: "todos"}, {Version: 3, Name: "pending"}}
+	applied := map[int64]bool{1: true, 2: true, 9: true}
+
+	versions := func(migrations []hasura.Migration) []int64 {
+		var response []int64
+		for _, item := range migrations {
+			response = append(response, item.Version)
+		}
+		return response
+	}
+
+	tests := []struct {
+		version string
+		steps   int
+		want    []int64
+	}{
+		{steps: 1, want: []int64{2}},
+		{steps: 5, want: []int64{2, 1}},
+		{version: "1", want: []int64{1}},
+		{version: "3"},
+	}
+
+	for _, test := range tests {
+		if got := versions(migrationsToRollback(migrations, applied, test.version, test.steps)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("migrationsToRollback(%q, %d) = %v, want %v", test.version, test.steps, got, test.want)
+		}
+	}
+
+	//  Columns are aligned, so compare their values only
+	var status []string
+	for _, line := range strings.Split(migrationStatus(migrations, applied), "\n") {
+		status = append(status, strings.Join(strings.Fields(line), " "))
+	}
+
+	for _, want := range []string{"2 todos Present Applied", "3 pending Present Not Applied", "9 - Not Present Applied"} {
+		if !strings.Contains(strings.Join(status, "\n"), want) {
+			t.Errorf("migrationStatus() = %s, want it to contain %q", status, want)
+		}
+	}
+}
+
+func TestSquashMigrations(t *testing.T) {
+
+	dir := t.TempDir()
+
+	var migrations []hasura.Migration
+	for version, name := range []string{"init", "todos"} {
+		location := filepath.Join(dir, fmt.Sprintf("%d_%s", version, name))
+		if err := os.MkdirAll(location, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		for file, content := range map[string]string{"up.sql": "CREATE TABLE " + name + " ();\n", "down.sql": "DROP TABLE " + name + ";\n"} {
+			if err := ioutil.WriteFile(filepath.Join(location, file), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		migrations = append(migrations, hasura.Migration{Version: int64(version), Name: name, Location: location})
+	}
+
+	up, down, err := squashMigrations(migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "-- 0_init\nCREATE TABLE init ();\n\n-- 1_todos\nCREATE TABLE todos ();\n"; up != want {
+		t.Errorf("squashMigrations() up = %q, want %q", up, want)
+	}
+
+	if want := "-- 1_todos\nDROP TABLE todos;\n\n-- 0_init\nDROP TABLE init;\n"; down != want {
+		t.Errorf("squashMigrations() down = %q, want %q", down, want)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+//  psqlCmd opens a psql session inside the postgres container
+var psqlCmd = &cobra.Command{
+	Use:   "psql [-- psql args...]",
+	Short: "Open a psql session on your local database",
+	Long: `Open an interactive psql session inside the postgres container
+of your running app, connected to the database of current branch.
+
+Everything after '--' is passed to psql as it is.`,
+	Example: `  nhost psql
+  nhost psql -- -c "select * from auth.users"`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		postgres := localPostgres()
+		user, password := databaseCredentials()
+
+		//  Postgres listens on the same port inside the container,
+		//  which is part of the name of it's unix socket
+		command := append([]string{"psql", "-U", user, "-p", fmt.Sprint(postgres.Port), "postgres"}, args...)
+
+		tty = term.IsTerminal(int(os.Stdin.Fd()))
+		execEnv = append(execEnv, "PGPASSWORD="+password)
+
+		code, err := execute(postgres, command, os.Stdin)
+		if err != nil {
+			log.WithField("service", "postgres").Debug(err)
+			status.Fatal("Failed to open psql session")
+		}
+
+		os.Exit(code)
+	},
+}
+
+//  Returns the running local postgres service
+func localPostgres() *nhost.Service {
+
+	//  Initialize the runtime environment
+	if err := env.Init(); err != nil {
+		log.Debug(err)
+		status.Fatal(util.WarnDockerNotFound)
+	}
+
+	service := env.Config.Services["postgres"]
+	if service == nil || service.ID == "" {
+		status.Fatal("Make sure your Nhost environment is running with `nhost dev`")
+	}
+
+	return service
+}
+
+//  Returns the user and password of the running postgres container
+func databaseCredentials() (string, string) {
+
+	user, password := nhost.DB_USER, nhost.DB_PASSWORD
+
+	data, err := env.Docker.ContainerInspect(env.Context, env.Config.Services["postgres"].ID)
+	if err != nil {
+		log.WithField("service", "postgres").Debug(err)
+		return user, password
+	}
+
+	for _, item := range data.Config.Env {
+		payload := strings.SplitN(item, "=", 2)
+		if len(payload) < 2 {
+			continue
+		}
+
+		switch payload[0] {
+		case "POSTGRES_USER":
+			user = payload[1]
+		case "POSTGRES_PASSWORD":
+			password = payload[1]
+		}
+	}
+
+	return user, password
+}
+
+func init() {
+	rootCmd.AddCommand(psqlCmd)
+}
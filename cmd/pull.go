@@ -26,227 +26,217 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 
+	"github.com/manifoldco/promptui"
 	"github.com/nhost/cli/hasura"
 	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
 	"github.com/spf13/cobra"
 )
 
-//  pullCmd represents the pull command
+var (
+
+	//  name of the pulled migration
+	pullName string
+
+	//  record the pulled migration as applied on production
+	force bool
+)
+
+//  pullCmd syncs the schema and metadata of production into local files
 var pullCmd = &cobra.Command{
 	Use:     "pull",
 	Aliases: []string{"p"},
-	Short:   "Pull migrations from remote",
-	Hidden:  true,
-	Long: `Pull latest migrations and metadata changes from remote
-and sync them with your local app.`,
-	/*
-		Run: func(cmd *cobra.Command, args []string) {
-
-			//  warn the user of upcoming dangers
-			log.Warn("This can potentially break your local changes")
-			status.Info("Backup your local changes before proceeding ahead")
-
-			//  configure interative prompt
-			confirmationPrompt := promptui.Prompt{
-				Label:     "Are you sure you want to continue",
-				IsConfirm: true,
-			}
+	Short:   "Pull schema and metadata changes from production",
+	Long: `Compare the schema of your linked production app with the one
+of your running app, and create a migration in nhost/migrations/default,
+containing only what production adds or changes. Objects which only
+exist locally are never dropped. Metadata of production is exported
+to nhost/metadata.
 
-			_, err := confirmationPrompt.Run()
-			if err != nil {
-				log.Debug(err)
-				os.Exit(0)
-			}
+Changes are previewed before anything is written.
 
-			//  validate authentication
-			user, err := validateAuth(nhost.AUTH_PATH)
-			if err != nil {
-				log.Debug(err)
-				status.Errorln("Failed to validate authentication")
+Production isn't modified, unless you use --force, which records
+the new migration as applied on it, since it already contains its changes.`,
+	Example: `  nhost pull
+  nhost pull --name add_todos --force`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		remote := hasura.Client{Client: &http.Client{}}
+		remote.Endpoint, remote.AdminSecret = productionHasura(cmd, args)
+
+		local := localHasuraClient()
 
-				//  begin the login procedure
-				loginCmd.Run(cmd, args)
+		status.Executing("Comparing your app with production")
+
+		migration, down, err := pullMigration(&remote, local, pullName)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to compare schema with production: %v", err))
+		}
+
+		metadata, err := remote.FetchMetadata()
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to export metadata of production: %v", err))
+		}
+
+		//  Local metadata may not exist yet
+		existing, err := hasura.LoadMetadata(nhost.METADATA_DIR)
+		if err != nil {
+			log.Debug(err)
+			existing = hasura.Metadata{}
+		}
+
+		diffs, err := hasura.DiffMetadata(existing, metadata, "local", "production")
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to compare metadata")
+		}
+
+		if len(migration.Data) == 0 && len(diffs) == 0 {
+			status.Successln("Your app is in sync with production")
+			return
+		}
+
+		//  Preview the changes
+		if len(migration.Data) > 0 {
+			printDiff(util.Diff("/dev/null", util.Rel(filepath.Join(migration.Location, "up.sql")), "", string(migration.Data)))
+		}
+		for _, item := range diffs {
+			printDiff(item.Diff)
+		}
+		fmt.Println()
+
+		if len(migration.Data) > 0 && !force {
+			status.Warnln("Production won't record the new migration as applied, so deploying it would apply it again. Use `--force` to record it")
+		}
+
+		//  if the user has not pre-approved the changes,
+		//  take the user's approval manually
+		if !approve {
+			prompt := promptui.Prompt{
+				Label:     "Write these changes",
+				IsConfirm: true,
 			}
 
-			var projects []nhost.Project
-			projects = append(projects, user.Projects...)
-			for _, item := range user.Teams {
-				projects = append(projects, item.Team.Projects...)
+			if _, err := prompt.Run(); err != nil {
+				os.Exit(0)
 			}
+		}
 
-			info, err := nhost.Info()
-			if err != nil {
+		if len(migration.Data) > 0 {
+			if err := writeMigration(migration, down); err != nil {
 				log.Debug(err)
-				log.Fatal("Failed to read saved Nhost app information")
+				status.Fatal("Failed to write migration")
 			}
 
-			var linkedProject nhost.Project
+			status.Successln(fmt.Sprintf("Created migration %s", util.Rel(migration.Location)))
 
-			for _, project := range projects {
-				if project.ID == info.ProjectID {
-					linkedProject = project
+			if force {
+				if err := remote.MarkMigrations(nhost.DATABASE, map[int64]bool{migration.Version: true}); err != nil {
+					log.Debug(err)
+					status.Fatal(fmt.Sprintf("Failed to record migration %d as applied on production", migration.Version))
 				}
-			}
-
-			//  intialize common options
-			hasuraEndpoint := "https://" + linkedProject.ProjectDomains.Hasura
-			adminSecret := linkedProject.HasuraGQEAdminSecret
 
-			commonOptions := []string{"--endpoint", hasuraEndpoint, "--admin-secret", adminSecret, "--skip-update-check"}
-
-			//  create migration
-			//  and notify remote to skip it
-
-			//  test new hasura client
-			hasuraClient := hasura.Client{}
-			hasuraClient.Init(hasuraEndpoint, adminSecret, nil)
+				status.Successln("Recorded it as applied on production")
+			}
+		}
 
-			_, err = pullMigration(hasuraClient, "pulled_from_remote", commonOptions)
-			if err != nil {
+		if len(diffs) > 0 {
+			if err := metadata.Write(nhost.METADATA_DIR); err != nil {
 				log.Debug(err)
-				log.Fatal("Failed to create migration from remote")
+				status.Fatal("Failed to write metadata")
 			}
 
+			status.Successln(fmt.Sprintf("Exported metadata of production to %s", util.Rel(nhost.METADATA_DIR)))
+		}
 
-			status.Info("Migrations pulled from remote")
-			status.Info("To apply the new migrations use `nhost dev`")
-		},
-	*/
+		status.Infoln("Restart `nhost dev` to apply the changes to your app")
+	},
 }
 
-func pullMigration(client hasura.Client, name string) (hasura.Migration, error) {
-
-	var args []string
-	var migration hasura.Migration
-	var execute exec.Cmd
+//  Creates a migration of the schema of the remote app, which isn't in the local one,
+//  along with the data of enum tables it creates. Without a local app, the whole
+//  schema of the remote app is part of the migration. Returns the migration,
+//  whose data is empty if the schemas are equal, and the SQL reverting it.
+func pullMigration(remote, local *hasura.Client, name string) (hasura.Migration, string, error) {
 
 	log.Debugf("Creating migration '%s'", name)
 
-	metadata, err := client.GetMetadata()
+	migration := (&hasura.Migration{Name: name}).Init(nhost.DATABASE)
+
+	remoteSchema, err := remote.DumpSchema()
 	if err != nil {
-		return migration, err
+		return migration, "", err
 	}
 
-	migration = hasura.Migration{
-		Name: name,
+	var localSchema string
+	if local != nil {
+		if localSchema, err = local.DumpSchema(); err != nil {
+			return migration, "", err
+		}
 	}
 
-	sourceName := "default"
-	if len(metadata.Sources) > 0 {
-		sourceName = metadata.Sources[0].Name
+	diff := hasura.DiffSchema(localSchema, remoteSchema)
+	if diff.Up == "" {
+		return migration, "", nil
 	}
 
-	migration = migration.Init(sourceName)
+	migration.Data = []byte(diff.Up)
 
-	//  Fetch list of all ALLOWED schemas before applying
-	schemas, err := client.GetSchemas()
+	//  Enum tables are useless without their values
+	metadata, err := remote.GetMetadata()
 	if err != nil {
-		log.Debug("Failed to get list of schemas")
-		return migration, err
+		return migration, "", err
 	}
 
-	var enumTables []hasura.TableEntry
-	var migrationTables []string
-	for _, source := range metadata.Sources {
-
-		//  Filter enum tables
-		enumTables = append(enumTables, filterEnumTables(source.Tables)...)
-
-		//	Filter migration tables
-		migrationTables = append(migrationTables, getMigrationTables(schemas, source.Tables)...)
-
+	created := make(map[string]bool)
+	for _, table := range diff.Tables {
+		created[table] = true
 	}
 
-	//  fetch migrations
-	if len(migrationTables) > 0 {
-
-		log.Debug("Creating initial migration")
-
-		migration.Data, err = client.Migration(migrationTables)
-		if err != nil {
-			log.Debug("Failed to get migration data")
-			return migration, err
-		}
-
-		// create migration file
-		if err := os.MkdirAll(migration.Location, os.ModePerm); err != nil {
-			log.Debug("Failed to create migration directory")
-			return migration, err
-		}
-
-		f, err := os.Create(filepath.Join(migration.Location, "up.sql"))
-		if err != nil {
-			log.Debug("Failed to create migration file")
-			return migration, err
-		}
-
-		if len(enumTables) > 0 {
-
-			log.Debug("Appending enum table seeds to initial migration")
-			seeds, err := client.ApplySeeds(enumTables)
-			if err != nil {
-				log.Debug("Failed to fetch seeds for enum tables")
-				return migration, err
+	var enumTables []hasura.TableEntry
+	for _, source := range metadata.Sources {
+		for _, table := range filterEnumTables(source.Tables) {
+			if created[table.Table.Schema+"."+table.Table.Name] {
+				enumTables = append(enumTables, table)
 			}
-
-			// append the fetched seed data
-			migration.Data = append(migration.Data, seeds...)
-		}
-
-		if _, err = f.Write(migration.Data); err != nil {
-			log.Debug("Failed to write migration file")
-			return migration, err
 		}
-
-		f.Sync()
-		f.Close()
 	}
 
-	log.Debug("Clearing remote migration for source: ", metadata.Sources[0].Name)
+	if len(enumTables) > 0 {
 
-	if err := client.ClearMigration(metadata.Sources[0].Name); err != nil {
-		return migration, err
-	}
-
-	log.Debug("Applying migrations")
-
-	args = []string{client.CLI, "migrate", "apply", "--skip-execution"}
-	args = append(args, client.CommonOptions...)
+		log.Debug("Appending enum table seeds to migration")
+		seeds, err := remote.ApplySeeds(enumTables)
+		if err != nil {
+			log.Debug("Failed to fetch seeds for enum tables")
+			return migration, "", err
+		}
 
-	execute = exec.Cmd{
-		Path: client.CLI,
-		Args: args,
-		Dir:  nhost.NHOST_DIR,
+		migration.Data = append(migration.Data, seeds...)
 	}
 
-	output, err := execute.CombinedOutput()
-	if err != nil {
-		log.Debug(string(output))
-		return migration, err
-	}
-
-	log.Debug("Export metadata")
+	return migration, diff.Down, nil
+}
 
-	args = []string{client.CLI, "metadata", "export"}
-	args = append(args, client.CommonOptionsWithoutDB...)
+//  Writes the up.sql and down.sql files of the migration
+func writeMigration(migration hasura.Migration, down string) error {
 
-	execute = exec.Cmd{
-		Path: client.CLI,
-		Args: args,
-		Dir:  nhost.NHOST_DIR,
+	if err := os.MkdirAll(migration.Location, os.ModePerm); err != nil {
+		return err
 	}
 
-	output, err = execute.CombinedOutput()
-	if err != nil {
-		log.Debug(string(output))
-		return migration, err
+	if err := ioutil.WriteFile(filepath.Join(migration.Location, "up.sql"), migration.Data, 0644); err != nil {
+		return err
 	}
 
-	return migration, nil
+	return ioutil.WriteFile(filepath.Join(migration.Location, "down.sql"), []byte(down), 0644)
 }
 
 func filterEnumTables(tables []hasura.TableEntry) []hasura.TableEntry {
@@ -262,44 +252,10 @@ func filterEnumTables(tables []hasura.TableEntry) []hasura.TableEntry {
 	return fromTables
 }
 
-func getMigrationTables(schemas []string, tables []hasura.TableEntry) []string {
-
-	var response []string
-
-	for _, table := range tables {
-		for _, schema := range schemas {
-			if table.Table.Schema == schema {
-				response = append(response, "--table")
-				response = append(response, fmt.Sprintf(
-					`%s.%s`,
-					schema,
-					table.Table.Name,
-				))
-			}
-		}
-	}
-
-	/*
-		for _, value := range filteredValues {
-			if value != "public.users" {
-				fromTables = append(fromTables, "--table")
-				fromTables = append(fromTables, value)
-			}
-		}
-	*/
-	return response
-}
-
 func init() {
-	//  rootCmd.AddCommand(pullCmd)
-
-	//  Here you will define your flags and configuration settings.
-
-	//  Cobra supports Persistent Flags which will work for this command
-	//  and all subcommands, e.g.:
-	//  pullCmd.PersistentFlags().String("foo", "", "A help for foo")
+	rootCmd.AddCommand(pullCmd)
 
-	//  Cobra supports local flags which will only run when this command
-	//  is called directly, e.g.:
-	//  pullCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	pullCmd.Flags().StringVar(&pullName, "name", "pulled_from_production", "Name of the migration")
+	pullCmd.Flags().BoolVar(&force, "force", false, "Record the migration as applied on production")
+	pullCmd.Flags().BoolVarP(&approve, "yes", "y", false, "Approve & bypass the confirmation prompt")
 }
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nhost/cli/hasura"
+	"github.com/nhost/cli/nhost"
+)
+
+//  Serves the schema dump, and the data of enum tables
+func schemaServer(schema string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Type string   `json:"type"`
+			Opts []string `json:"opts"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch {
+		case r.URL.Path == "/v2/query":
+			fmt.Fprint(w, `{"result_type": "TuplesOk", "result": [["schema_name"], ["public"], ["auth"], ["hdb_catalog"]]}`)
+		case r.URL.Path == "/v1/metadata":
+			fmt.Fprint(w, `{"metadata": {"sources": [{"name": "default", "tables": [{"table": {"schema": "public", "name": "roles"}, "is_enum": true}, {"table": {"schema": "public", "name": "todos"}}]}]}}`)
+		case strings.Join(body.Opts, " ") == "--no-owner --no-acl --data-only --column-inserts --table public.roles":
+			fmt.Fprint(w, "INSERT INTO public.roles (value) VALUES ('admin');\n")
+		case strings.Join(body.Opts, " ") == "--schema-only --no-owner --no-acl --schema public":
+			fmt.Fprint(w, schema)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestPullMigration(t *testing.T) {
+
+	defer func(database string) { nhost.DATABASE = database }(nhost.DATABASE)
+	nhost.DATABASE = "default"
+
+	todos := "CREATE TABLE public.todos (\n    id integer NOT NULL\n);\n"
+	roles := "CREATE TABLE public.roles (\n    value text NOT NULL\n);\n"
+
+	remoteServer := schemaServer(roles + todos)
+	defer remoteServer.Close()
+
+	//  Tables of local migrations, which haven't been deployed yet, are kept
+	localServer := schemaServer(todos + "CREATE TABLE public.drafts (\n    id integer NOT NULL\n);\n")
+	defer localServer.Close()
+
+	remote := hasura.Client{Endpoint: remoteServer.URL, Client: &http.Client{}}
+	local := hasura.Client{Endpoint: localServer.URL, Client: &http.Client{}}
+
+	//  Only the difference is pulled, along with the values of new enum tables
+	migration, down, err := pullMigration(&remote, &local, "roles")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data := string(migration.Data); !strings.HasPrefix(data, "CREATE TABLE public.roles (") || strings.Contains(data, "public.todos") || !strings.HasSuffix(data, "VALUES ('admin');\n") {
+		t.Errorf("pullMigration() = %s, want only roles and their values", data)
+	}
+
+	if strings.Contains(string(migration.Data), "DROP") {
+		t.Errorf("pullMigration() = %s, want nothing to be dropped", migration.Data)
+	}
+
+	if down != "DROP TABLE IF EXISTS public.roles;\n" {
+		t.Errorf("pullMigration() down = %q, want roles to be dropped", down)
+	}
+
+	//  Without a local app, the whole schema is pulled
+	migration, _, err = pullMigration(&remote, nil, "init")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(migration.Data), "CREATE TABLE public.todos") {
+		t.Errorf("pullMigration() = %s, want todos too", migration.Data)
+	}
+
+	//  Equal schemas need no migration
+	migration, _, err = pullMigration(&local, &local, "nothing")
+	if err != nil || len(migration.Data) != 0 {
+		t.Errorf("pullMigration() of equal schemas = %s, %v, want nothing", migration.Data, err)
+	}
+}
@@ -38,11 +38,20 @@ var purgeCmd = &cobra.Command{
 	Aliases:    []string{"down"},
 	SuggestFor: []string{"dev"},
 	Short:      "Delete all containers created by `nhost dev`",
-	Long: `If you have changed your nhost/config.yaml, 
-then use this command to delete all your container.
-And re-create them next time you run 'nhost dev'`,
+	Long: `Delete all your containers, and re-create them
+next time you run 'nhost dev'.
+
+Changes to your nhost/config.yaml don't require a purge,
+since 'nhost dev' re-creates the containers whose configuration has changed.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 
+		//  Stop the background process of a detached app, if any,
+		//  before deleting the containers it's serving.
+		if _, err := stopDaemon(); err != nil {
+			log.Debug(err)
+			status.Errorln("Failed to stop the background process")
+		}
+
 		//  Initialize the runtime environment
 		if err := env.Init(); err != nil {
 			log.Debug(err)
@@ -76,6 +85,11 @@ And re-create them next time you run 'nhost dev'`,
 			env.Network, _ = env.GetNetwork()
 		}
 		env.RemoveNetwork()
+
+		//  Network of older CLIs, whose containers have been removed above
+		if err := env.RemoveLegacyNetwork(); err != nil {
+			log.Debug(err)
+		}
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 
@@ -0,0 +1,235 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nhost/cli/environment"
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/proxy"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+)
+
+//  only stop, and don't remove, the containers after the command exits
+var keepContainers bool
+
+//  runCmd starts the app, runs a command against it, and tears it down
+var runCmd = &cobra.Command{
+	Use:        "run [-p port] -- <command> [args...]",
+	SuggestFor: []string{"dev", "execute"},
+	Short:      "Run a command against your local app, without any prompts",
+	Long: `Start your local app, wait for migrations, metadata
+and seeds to be applied, and run the given command.
+
+NHOST_BACKEND_URL, admin secret and JWT secret of the app
+are available in the environment of the command.
+
+Once the command exits, the app is torn down,
+and 'nhost run' exits with the exit code of the command.
+Useful for CI pipelines and git hooks.`,
+	Example: `  nhost run -- npm test
+  nhost run --port 8000 -- go test ./...`,
+	Args: cobra.MinimumNArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+
+		//  check if nhost/ exists
+		if !util.PathExists(nhost.NHOST_DIR) {
+			status.Info("Initialize new app by running 'nhost init'")
+			return errors.New("app not found in this directory")
+		}
+
+		//  create .nhost/ if it doesn't exist
+		if err := os.MkdirAll(nhost.DOT_NHOST, os.ModePerm); err != nil {
+			status.Errorln("Failed to initialize nhost data directory")
+			return err
+		}
+
+		//	Containers of the app are shared with `nhost dev`
+		if daemon, err := nhost.LoadDaemon(); err == nil && util.ProcessRunning(daemon.PID) {
+			status.Infoln("Stop it with `nhost stop`, or run your command against it directly")
+			return fmt.Errorf("your app is already running in background at http://localhost:%s", daemon.Port)
+		}
+
+		if !util.PortAvailable(env.Port) {
+			status.Info("Choose a different port with `nhost run [--port]`")
+			return fmt.Errorf("port %s not available", env.Port)
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+
+		os.Exit(runHeadless(args))
+	},
+}
+
+//	Starts the app, runs the command, tears the app down,
+//	and returns the exit code of the command.
+func runHeadless(args []string) int {
+
+	env.KeepContainers = keepContainers
+
+	//  Initialize the runtime environment
+	if err := env.Init(); err != nil {
+		log.Debug(err)
+		status.Errorln(util.WarnDockerNotFound)
+		return 1
+	}
+
+	env.UpdateState(environment.Executing)
+
+	//  Parse the nhost/config.yaml
+	if err := env.Config.Wrap(); err != nil {
+		log.Debug(err)
+		status.Errorln("Failed to read Nhost config")
+		return 1
+	}
+
+	//  Start a branch which has never run with data of it's parent branch, if configured
+	env.InheritData("")
+
+	//  Initialize cancellable context for this specific execution
+	env.ExecutionContext, env.ExecutionCancel = context.WithCancel(env.Context)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	//	Abort the startup on signal interruption
+	started := make(chan error, 1)
+	go func() {
+		started <- env.Execute()
+	}()
+
+	select {
+	case err := <-started:
+		if err != nil {
+			log.Debug(err)
+			status.Errorln("Failed to initialize your environment")
+			env.Cleanup()
+			return 1
+		}
+	case <-signals:
+		env.ExecutionCancel()
+		<-started
+		env.Cleanup()
+		return 130
+	}
+
+	//  Start functions
+	if err := prepareFunctionServer(); err != nil {
+		log.Debug(err)
+	} else if err := ServeFuncs(); err == nil {
+		env.Servers = append(env.Servers, functionServer.Server)
+	}
+
+	//  Make all services available on NHOST_BACKEND_URL
+	reverseproxy := proxy.New(&proxy.ServerConfig{
+		Port:        env.Port,
+		Environment: &env,
+		Log:         log,
+	})
+
+	reverseproxy.AddService(&proxy.Service{
+		Name:    "functions",
+		Routes:  []proxy.Route{{Name: "Functions", Source: "/", Destination: "/v1/functions/"}},
+		Port:    funcPort,
+		Address: fmt.Sprintf("http://localhost:%v", funcPort),
+	})
+
+	registerServices(reverseproxy)
+	go serveProxy(reverseproxy)
+
+	//	Wait for the proxy to start listening
+	for attempt := 0; attempt < 50 && util.PortAvailable(env.Port); attempt++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	env.UpdateState(environment.Active)
+
+	code := runCommand(args, signals)
+
+	env.Cleanup()
+	return code
+}
+
+//	Runs the command with runtime variables of the app,
+//	forwarding any signal interruption to it.
+func runCommand(args []string, signals chan os.Signal) int {
+
+	execute := exec.Command(args[0], args[1:]...)
+	execute.Stdin = os.Stdin
+	execute.Stdout = os.Stdout
+	execute.Stderr = os.Stderr
+	execute.Env = append(os.Environ(), util.MapToStringArray(util.RuntimeVars(env.Port, false))...)
+
+	if err := execute.Start(); err != nil {
+		log.Debug(err)
+		status.Errorln(fmt.Sprintf("Failed to run %s", args[0]))
+		return 127
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if err := execute.Process.Signal(sig); err != nil {
+					log.Debug(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if err := execute.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			return exitErr.ExitCode()
+		}
+		log.Debug(err)
+		return 1
+	}
+
+	return 0
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringVarP(&env.Port, "port", "p", "1337", "Port for dev proxy")
+	runCmd.Flags().BoolVar(&keepContainers, "keep", false, "Only stop, and don't remove, the containers afterwards")
+}
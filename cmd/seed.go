@@ -0,0 +1,166 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+
+	//  seeds to apply, all of them if empty
+	seedFiles []string
+
+	//  re-apply seeds which have been applied before
+	resetSeeds bool
+
+	//  tables to export the rows of into a new seed
+	seedTables []string
+)
+
+//  seedCmd manages the seeds of the database
+var seedCmd = &cobra.Command{
+	Use:     "seed",
+	Aliases: []string{"seeds"},
+	Short:   "Manage the seeds of your database",
+	Long: `Seeds are SQL files in nhost/seeds/default,
+which fill your database with data, in order of their names.
+
+Applied seeds are recorded with their checksums,
+so that every one is only applied once.`,
+}
+
+//  seedApplyCmd applies the seeds to the local database
+var seedApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply seeds to your local database",
+	Long: `Apply the seeds, which haven't been applied yet, to the database
+of your running app, in order of their names.
+Every seed is applied in it's own transaction.
+
+Seeds which have changed since they were applied are only applied again
+with --reset. Mention seeds with --file to apply only those,
+and with --reset, even if they haven't changed.`,
+	Example: `  nhost seed apply
+  nhost seed apply --reset
+  nhost seed apply --file 1637000000000_users.sql --reset`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		env.Hasura = localHasuraClient()
+
+		status.Executing("Applying seeds")
+		applied, changed, err := env.ApplySeeds(nhost.SeedsDir(), seedFiles, resetSeeds)
+		for _, item := range applied {
+			status.Successln(fmt.Sprintf("Applied %s", item.Name))
+		}
+
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(err.Error())
+		}
+
+		for _, item := range changed {
+			status.Warnln(fmt.Sprintf("Seed %s has changed since it was applied, re-apply it with `--reset`", item.Name))
+		}
+
+		if len(applied) == 0 {
+			status.Infoln("No seeds to apply")
+		}
+	},
+}
+
+//  seedCreateCmd creates a new seed file
+var seedCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a new seed",
+	Long: `Create a new seed file in nhost/seeds/default,
+prefixed with a timestamp to apply it after existing ones.
+
+With --from-table, the current rows of the tables in your local
+database are exported into the seed, as INSERT statements.`,
+	Example: `  nhost seed create roles
+  nhost seed create users --from-table auth.users --from-table public.profiles`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		name := args[0]
+		if !regexp.MustCompile(`^[A-Za-z0-9_-]+$`).MatchString(name) {
+			status.Fatal(fmt.Sprintf("Invalid seed name %q, only letters, digits, '_' and '-' are allowed", name))
+		}
+
+		data := []byte(fmt.Sprintf("-- Seed: %s\n", name))
+
+		if len(seedTables) > 0 {
+			client := localHasuraClient()
+
+			options := []string{"--data-only", "--column-inserts", "--no-owner", "--no-acl"}
+			for _, item := range seedTables {
+				options = append(options, "--table", item)
+			}
+
+			status.Executing("Exporting rows")
+			dump, err := client.PGDump(options)
+			if err != nil {
+				log.Debug(err)
+				status.Fatal(fmt.Sprintf("Failed to export rows: %v", err))
+			}
+
+			data = append(data, dump...)
+		}
+
+		path := filepath.Join(nhost.SeedsDir(), fmt.Sprintf("%d_%s.sql", time.Now().UnixNano()/int64(time.Millisecond), name))
+
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to create seeds directory")
+		}
+
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to create seed")
+		}
+
+		status.Successln(fmt.Sprintf("Created seed %s", util.Rel(path)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+	seedCmd.AddCommand(seedApplyCmd)
+	seedCmd.AddCommand(seedCreateCmd)
+
+	seedApplyCmd.Flags().StringArrayVarP(&seedFiles, "file", "f", nil, "Seed to apply, can be repeated")
+	seedApplyCmd.Flags().BoolVar(&resetSeeds, "reset", false, "Apply seeds again, which have been applied before")
+	seedCreateCmd.Flags().StringArrayVar(&seedTables, "from-table", nil, "Table to export the rows of, like public.roles, can be repeated")
+}
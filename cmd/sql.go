@@ -0,0 +1,196 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+
+	//  file to read the SQL from
+	sqlFile string
+
+	//  output format of query results
+	sqlOutput string
+)
+
+//  sqlCmd runs SQL on the local database
+var sqlCmd = &cobra.Command{
+	Use:   "sql [query]",
+	Short: "Run SQL on your local database",
+	Long: `Run SQL on the database of your running app, through Hasura,
+and print the results as a table, CSV or JSON.`,
+	Example: `  nhost sql "select id, email from auth.users"
+  nhost sql -f seeds/users.sql
+  nhost sql "select * from todos" -o csv > todos.csv`,
+	Args: cobra.MaximumNArgs(1),
+	PreRun: func(cmd *cobra.Command, args []string) {
+
+		switch sqlOutput {
+		case "table", "csv", "json":
+		default:
+			status.Fatal(fmt.Sprintf("Invalid output format %q, use table, csv or json", sqlOutput))
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+
+		var query string
+
+		switch {
+		case len(args) > 0 && sqlFile != "":
+			status.Fatal("Mention either a query, or a file with `--file`, not both")
+		case len(args) > 0:
+			query = args[0]
+		case sqlFile != "":
+			data, err := ioutil.ReadFile(sqlFile)
+			if err != nil {
+				log.Debug(err)
+				status.Fatal(fmt.Sprintf("Failed to read %s", sqlFile))
+			}
+			query = string(data)
+		default:
+			status.Fatal("Mention the query to run, or a file with `--file`")
+		}
+
+		client := localHasuraClient()
+
+		result, err := client.RunSQL(query)
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(fmt.Sprintf("Failed to run SQL: %v", err))
+		}
+
+		//  Queries like INSERT without RETURNING have no rows
+		if len(result.Rows) == 0 {
+			status.Successln("Query executed")
+			return
+		}
+
+		switch sqlOutput {
+		case "csv":
+			err = printCSV(result.Rows)
+		case "json":
+			err = printJSON(result.Rows)
+		default:
+			printTable(result.Rows)
+		}
+
+		if err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to print the results")
+		}
+	},
+}
+
+//  Prints the rows as a table, with the first one as header
+func printTable(rows [][]interface{}) {
+
+	p := newPrinter()
+	p.print("header", "", "")
+
+	for index, row := range rows {
+		var values, underlines []string
+		for _, value := range row {
+			if value == nil {
+				value = "NULL"
+			}
+			values = append(values, fmt.Sprint(value))
+			underlines = append(underlines, strings.Repeat("-", len(fmt.Sprint(value))))
+		}
+
+		fmt.Fprintln(p, strings.Join(values, "\t"))
+		if index == 0 {
+			fmt.Fprintln(p, strings.Join(underlines, "\t"))
+		}
+	}
+
+	p.close()
+
+	if len(rows) == 2 {
+		status.Infoln("1 row")
+	} else {
+		status.Infoln(fmt.Sprintf("%d rows", len(rows)-1))
+	}
+}
+
+//  Prints the rows as CSV, with NULL as empty value
+func printCSV(rows [][]interface{}) error {
+
+	writer := csv.NewWriter(os.Stdout)
+
+	for _, row := range rows {
+		var values []string
+		for _, value := range row {
+			if value == nil {
+				value = ""
+			}
+			values = append(values, fmt.Sprint(value))
+		}
+
+		if err := writer.Write(values); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+//  Prints the rows as a JSON array of objects, keyed by column names
+func printJSON(rows [][]interface{}) error {
+
+	response := make([]map[string]interface{}, 0, len(rows)-1)
+
+	for _, row := range rows[1:] {
+		item := make(map[string]interface{})
+		for index, value := range row {
+			if index < len(rows[0]) {
+				item[fmt.Sprint(rows[0][index])] = value
+			}
+		}
+		response = append(response, item)
+	}
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(sqlCmd)
+	sqlCmd.Flags().StringVarP(&sqlFile, "file", "f", "", "File to read the SQL from")
+	sqlCmd.Flags().StringVarP(&sqlOutput, "output", "o", "table", "Output format of results: table, csv or json")
+}
@@ -0,0 +1,186 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+)
+
+//  print only the ports of services
+var showPorts bool
+
+//  statusCmd prints the state of the local environment
+var statusCmd = &cobra.Command{
+	Use:        "status",
+	Aliases:    []string{"ps"},
+	SuggestFor: []string{"logs", "stop"},
+	Short:      "Show the status of your local app",
+	Long: `Print the health, ports and versions of all services
+of your local app, along with the background process
+started by 'nhost dev --detach', if any.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+
+		//  Initialize the runtime environment
+		if err := env.Init(); err != nil {
+			log.Debug(err)
+			status.Fatal(util.WarnDockerNotFound)
+		}
+
+		//  if no containers found - abort the execution,
+		//  unless only the ports are requested, which are known without containers
+		if len(env.Config.Services) == 0 && !showPorts {
+			status.Info(util.InfoServicesRunning)
+			status.Fatal(util.ErrServicesNotFound)
+		}
+
+		//  Parse the nhost/config.yaml to load service versions
+		if err := env.Config.Wrap(); err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to read Nhost config")
+		}
+
+		//  Re-load the IDs and ports of running containers,
+		//  since parsing the configuration may have assigned new ones.
+		containers, err := env.GetContainers()
+		if err != nil {
+			log.Debug(err)
+			status.Fatal(util.ErrServicesNotFound)
+		}
+		env.WrapContainersAsServices(containers)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+
+		status.Clean()
+
+		if showPorts {
+			printPorts()
+			return
+		}
+
+		p := newPrinter()
+		p.print("header", "", "")
+
+		if daemon, err := nhost.LoadDaemon(); err == nil && util.ProcessRunning(daemon.PID) {
+			p.print("", "App", fmt.Sprintf("http://localhost:%s %s(%s)%s", daemon.Port, util.Gray, daemon.Status, util.Reset))
+			p.print("", "Process", fmt.Sprintf("%d %sstarted %s ago%s", daemon.PID, util.Gray, time.Since(daemon.Started).Round(time.Second), util.Reset))
+			p.print("", "Output", daemon.Log)
+			p.close()
+		}
+
+		var names []string
+		for name := range env.Config.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(p, "Service\tStatus\tPort\tVersion")
+		fmt.Fprintln(p, "-------\t------\t----\t-------")
+
+		for _, name := range names {
+			service := env.Config.Services[name]
+
+			state := "remote"
+			if !service.NoContainer {
+				var err error
+				state, err = service.State(env.Docker, env.Context)
+				if err != nil {
+					log.WithField("service", name).Debug(err)
+					state = "unknown"
+				}
+			}
+
+			//  Services with a health check can be additionally validated
+			if state == "running" && service.HealthCheck != nil {
+				if err := service.Probe(env.Docker, env.Context); err == nil {
+					state = fmt.Sprint(util.Green, "healthy", util.Reset)
+				} else {
+					state = fmt.Sprint(util.Yellow, "unhealthy", util.Reset)
+				}
+			}
+
+			version := fmt.Sprint(service.Version)
+			if service.Version == nil {
+				version = "-"
+			}
+
+			fmt.Fprintf(p, "%s\t%s\t%d\t%s%s%s\n", strings.Title(name), state, service.Port, util.Gray, version, util.Reset)
+		}
+
+		p.close()
+	},
+}
+
+//  Prints the ports, and local addresses, of all services
+func printPorts() {
+
+	p := newPrinter()
+
+	var names []string
+	for name := range env.Config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(p, "Service\tPort\tAddress")
+	fmt.Fprintln(p, "-------\t----\t-------")
+
+	for _, name := range names {
+		service := env.Config.Services[name]
+
+		//  Services without published ports, like background workers
+		if service.Port == 0 {
+			continue
+		}
+
+		address := fmt.Sprintf("http://localhost:%d", service.Port)
+		switch {
+		case service.NoContainer:
+			address = service.Address
+		case name == "postgres":
+			user, password := service.Environment["postgres_user"], service.Environment["postgres_password"]
+			if user == nil || password == nil {
+				user, password = nhost.DB_USER, nhost.DB_PASSWORD
+			}
+			address = fmt.Sprintf("postgres://%v:%v@localhost:%d/postgres", user, password, service.Port)
+		}
+
+		fmt.Fprintf(p, "%s\t%d\t%s\n", strings.Title(name), service.Port, address)
+	}
+
+	p.close()
+	status.Info(fmt.Sprintf("%sPorts are saved in %s%s", util.Gray, nhost.PortsPath(), util.Reset))
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&showPorts, "ports", false, "Only show the ports and addresses of services")
+}
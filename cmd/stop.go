@@ -0,0 +1,72 @@
+/*
+MIT License
+
+Copyright (c) Nhost
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"github.com/nhost/cli/util"
+	"github.com/spf13/cobra"
+)
+
+//  stopCmd stops the local environment without deleting anything
+var stopCmd = &cobra.Command{
+	Use:        "stop",
+	SuggestFor: []string{"purge", "dev"},
+	Short:      "Stop your local app without deleting containers",
+	Long: `Stop the background process started by 'nhost dev --detach',
+along with all the service containers of your app.
+
+Unlike 'nhost purge', containers are not deleted,
+and will be resumed next time you run 'nhost dev'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		status.Executing("Stopping your app")
+
+		//  Stop the background process first,
+		//  otherwise it would keep serving the proxy and functions.
+		if _, err := stopDaemon(); err != nil {
+			log.Debug(err)
+			status.Errorln("Failed to stop the background process")
+		}
+
+		//  Initialize the runtime environment
+		if err := env.Init(); err != nil {
+			log.Debug(err)
+			status.Fatal(util.WarnDockerNotFound)
+		}
+
+		//  Make sure containers are stopped,
+		//  even if the background process was killed abruptly.
+		if err := env.Shutdown(false, env.Context); err != nil {
+			log.Debug(err)
+			status.Fatal("Failed to stop Nhost services")
+		}
+
+		status.Successln("Your app has been stopped. Resume it with `nhost dev`")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+}
@@ -3,14 +3,13 @@ package environment
 import (
 	"context"
 	"fmt"
-	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
-	client "github.com/docker/docker/client"
 	"github.com/nhost/cli/nhost"
 	"github.com/nhost/cli/util"
 	"github.com/sirupsen/logrus"
@@ -27,7 +26,16 @@ func (e *Environment) WrapContainersAsServices(containers []types.Container) err
 
 	for _, container := range containers {
 		nameWithPrefix := strings.Split(container.Names[0], "/")[1]
-		name := strings.TrimPrefix(nameWithPrefix, nhost.PREFIX+"_")
+
+		//  Prefer the service label, and fallback to the container name,
+		//  which has the legacy prefix for containers of older CLIs
+		name, ok := container.Labels[nhost.LABEL_SERVICE]
+		if !ok {
+			name = strings.TrimPrefix(nameWithPrefix, nhost.PREFIX+"_")
+			if container.Labels[nhost.LABEL_PROJECT] == "" {
+				name = strings.TrimPrefix(name, nhost.LEGACY_PREFIX+"_")
+			}
+		}
 		if e.Config.Services[name] == nil {
 			e.Config.Services[name] = &nhost.Service{
 
@@ -116,20 +124,161 @@ func (e *Environment) Shutdown(purge bool, ctx context.Context) error {
 	return response
 }
 
-//  returns the list of running containers whose names have specified prefix
+//  Removes the containers whose configuration no longer matches
+//  the one generated from config.yaml, so that they get re-created.
+//  Data is mounted from the host, and therefore, it's retained.
+func (e *Environment) RecreateChanged() error {
+
+	log.Debug("Checking containers for configuration changes")
+
+	for name, service := range e.Config.Services {
+
+		if service.ID == "" || service.Config == nil || service.NoContainer {
+			continue
+		}
+
+		changes, err := service.Inspect(e.Docker, e.ExecutionContext)
+		if err != nil {
+
+			//  Container may have been removed outside of Nhost
+			log.WithField("service", name).Debug(err)
+			service.Reset()
+			continue
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		status.Infoln(fmt.Sprintf("Recreating %s: %s", name, strings.Join(changes, ", ")))
+
+		if err := service.Remove(e.Docker, e.ExecutionContext); err != nil {
+			return err
+		}
+
+		service.Reset()
+	}
+
+	return nil
+}
+
+//  returns the list of containers labelled with this app's project,
+//  along with the ones created by older CLIs, before containers were labelled
 func (e *Environment) GetContainers() ([]types.Container, error) {
 
 	log.WithFields(logrus.Fields{
-		"type":   "prefix",
-		"prefix": nhost.PREFIX,
+		"type":    "project",
+		"project": nhost.PREFIX,
 	}).Debug("Fetching containers")
 
-	f := filters.NewArgs(filters.KeyValuePair{
-		Key:   "name",
-		Value: nhost.PREFIX,
+	response, err := e.Docker.ContainerList(e.Context, types.ContainerListOptions{All: true, Filters: nhost.ProjectFilter()})
+	if err != nil {
+		return response, err
+	}
+
+	legacy, err := e.legacyContainers()
+	if err != nil {
+		log.Debug(err)
+	}
+
+	return append(response, legacy...), nil
+}
+
+//  Returns the containers created by older CLIs, which have no labels,
+//  and are named with the legacy prefix, instead of the app's one.
+//  They are only found until they are removed, or re-created with labels.
+//
+//  Every app used the same legacy names, so only the containers
+//  mounting directories of this app are considered it's own.
+func (e *Environment) legacyContainers() ([]types.Container, error) {
+
+	var response []types.Container
+
+	containers, err := e.Docker.ContainerList(e.Context, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("name", fmt.Sprintf("^/%s_", nhost.LEGACY_PREFIX))),
 	})
+	if err != nil {
+		return response, err
+	}
+
+	for _, item := range containers {
+		if item.Labels[nhost.LABEL_PROJECT] == "" && mountsApp(item) {
+			response = append(response, item)
+		}
+	}
+
+	return response, nil
+}
+
+//  Checks whether any bind mount of the container is in nhost or .nhost directories of this app
+func mountsApp(item types.Container) bool {
+
+	for _, mount := range item.Mounts {
+		for _, dir := range []string{nhost.NHOST_DIR, filepath.Join(util.WORKING_DIR, ".nhost")} {
+			if relative, err := filepath.Rel(dir, mount.Source); err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+				return true
+			}
+		}
+	}
 
-	return e.Docker.ContainerList(e.Context, types.ContainerListOptions{All: true, Filters: f})
+	return false
+}
+
+//  Fetches the ID of the network created by older CLIs, before networks were labelled
+func (e *Environment) legacyNetwork() (string, error) {
+
+	response, err := e.Docker.NetworkList(e.Context, types.NetworkListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", nhost.LEGACY_PREFIX)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, item := range response {
+		if item.Name == nhost.LEGACY_PREFIX && item.Labels[nhost.LABEL_PROJECT] == "" {
+			return item.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+//  Removes the network created by older CLIs, if it exists
+func (e *Environment) RemoveLegacyNetwork() error {
+
+	network, err := e.legacyNetwork()
+	if err != nil || network == "" {
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"type":    "network",
+		"network": nhost.LEGACY_PREFIX,
+	}).Debug("Removing legacy network")
+
+	return e.Docker.NetworkRemove(e.Context, network)
+}
+
+//  Returns the runner of scripts on the data in .nhost,
+//  in a container of the image the app's database runs on,
+//  so that the users owning the data are the same.
+func (e *Environment) DataRunner() nhost.DataRunner {
+
+	definition := nhost.DEFINITIONS["postgres"]
+	image := fmt.Sprintf("%s:%v", definition.Image, definition.Version)
+
+	if postgres := e.Config.Services["postgres"]; postgres != nil {
+		if postgres.Image != "" {
+			image = fmt.Sprintf("%s:%v", postgres.Image, postgres.Version)
+		} else if postgres.ID != "" {
+			if data, err := e.Docker.ContainerInspect(e.Context, postgres.ID); err == nil {
+				image = data.Config.Image
+			}
+		}
+	}
+
+	return nhost.DataContainer(e.Docker, e.Context, image)
 }
 
 //  removes a given network by ID
@@ -151,14 +300,16 @@ func (e *Environment) PruneNetworks() error {
 		"value": nhost.PREFIX,
 	}).Debug("Pruning")
 
-	f := filters.NewArgs(filters.KeyValuePair{
-		Key:   "name",
-		Value: nhost.PREFIX,
-	})
+	if _, err := e.Docker.NetworksPrune(e.Context, nhost.ProjectFilter()); err != nil {
+		return err
+	}
 
-	_, err := e.Docker.NetworksPrune(e.Context, f)
+	//  Networks of older CLIs are removed only if they are unused
+	if err := e.RemoveLegacyNetwork(); err != nil {
+		log.Debug(err)
+	}
 
-	return err
+	return nil
 }
 
 //  prune unused containers
@@ -169,16 +320,24 @@ func (e *Environment) PruneContainers() error {
 		"value": nhost.PREFIX,
 	}).Debug("Pruning")
 
-	/*
-		f := filters.NewArgs(filters.KeyValuePair{
-			Key:   "name",
-			Value: nhost.PREFIX,
-		})
-	*/
+	if _, err := e.Docker.ContainersPrune(e.Context, nhost.ProjectFilter()); err != nil {
+		return err
+	}
 
-	_, err := e.Docker.ContainersPrune(e.Context, filters.Args{})
+	legacy, err := e.legacyContainers()
+	if err != nil {
+		return err
+	}
 
-	return err
+	for _, item := range legacy {
+		if item.State != "running" {
+			if err := e.Docker.ContainerRemove(e.Context, item.ID, types.ContainerRemoveOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
 
 //  If docker network exists -> fetches it's ID
@@ -214,6 +373,9 @@ func (e *Environment) PrepareNetwork() error {
 		//  create new network if no network such exists
 		net, err := e.Docker.NetworkCreate(e.Context, nhost.PREFIX, types.NetworkCreate{
 			CheckDuplicate: true,
+			Labels: map[string]string{
+				nhost.LABEL_PROJECT: nhost.PREFIX,
+			},
 		})
 		if err != nil {
 			return err
@@ -223,7 +385,7 @@ func (e *Environment) PrepareNetwork() error {
 	return nil
 }
 
-//  fetches ID of docker network by name
+//  fetches ID of docker network by project label
 func (e *Environment) GetNetwork() (string, error) {
 
 	log.WithFields(logrus.Fields{
@@ -231,96 +393,11 @@ func (e *Environment) GetNetwork() (string, error) {
 		"network": nhost.PREFIX,
 	}).Debug("Fetching")
 
-	f := filters.NewArgs(filters.KeyValuePair{
-		Key:   "name",
-		Value: nhost.PREFIX,
-	})
-
 	response, err := e.Docker.NetworkList(e.Context, types.NetworkListOptions{
-		Filters: f,
+		Filters: nhost.ProjectFilter(),
 	})
 	if len(response) > 0 && err == nil {
 		return response[0].ID, err
 	}
 	return "", err
 }
-
-//
-//  Prepare func validates whether all required images exist,
-//  and downloads any one which doesn't.
-//
-func (e *Environment) CheckImages() error {
-
-	var err error
-
-	log.Debug("Checking for required Nhost container images")
-
-	//  check if a required image already exists
-	//  if it doesn't -> then pull it
-
-	requiredImages := []string{}
-	for _, service := range e.Config.Services {
-
-		//  only those services which actually have an image
-		//  this check is being added to exclude NHOST_FUNCTIONS
-		if service.Image != "" {
-			requiredImages = append(requiredImages, fmt.Sprintf("%s:%v", service.Image, service.Version))
-		}
-	}
-
-	availableImages, err := e.Docker.ImageList(e.Context, types.ImageListOptions{All: true})
-	if err != nil {
-		return err
-	}
-
-	//	image waiter group
-	var image_waiter sync.WaitGroup
-
-	for _, requiredImage := range requiredImages {
-		available := false
-		for _, image := range availableImages {
-
-			//  check wether the image is available or not
-			if util.Contains(image.RepoTags, requiredImage) {
-				available = true
-			}
-		}
-
-		//  if it NOT available, then pull the image
-		if !available {
-			image_waiter.Add(1)
-			status.Total += 1
-			go func(image string, client *client.Client) {
-				err = pullImage(client, image)
-				image_waiter.Done()
-				status.Value += 1
-				if err != nil {
-					log.Debug(err)
-					status.Error("Failed to pull image: " + image)
-					status.Infoln("Pull it manually with `docker image pull " + image + " && nhost dev`")
-				}
-			}(requiredImage, e.Docker)
-		}
-	}
-
-	image_waiter.Wait()
-
-	return err
-}
-
-func pullImage(cli *client.Client, tag string) error {
-
-	log.WithField("component", tag).Debug("Pulling container image")
-
-	/* 	out, err := cli.ImagePull(context.Background(), tag, types.ImagePullOptions{})
-	   	out.Close()
-	*/
-	dockerCLI, _ := exec.LookPath("docker")
-	cmd := exec.Cmd{
-		Args: []string{dockerCLI, "image", "pull", tag},
-		Path: dockerCLI,
-		//	Stdout: os.Stdout,
-	}
-
-	return cmd.Run()
-}
@@ -0,0 +1,43 @@
+package environment
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+)
+
+func TestMountsApp(t *testing.T) {
+
+	defer func(dir, nhostDir string) { util.WORKING_DIR, nhost.NHOST_DIR = dir, nhostDir }(util.WORKING_DIR, nhost.NHOST_DIR)
+	util.WORKING_DIR = filepath.Join("/home", "app")
+	nhost.NHOST_DIR = filepath.Join(util.WORKING_DIR, "nhost")
+
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		{name: "data of this app", source: filepath.Join("/home", "app", ".nhost", "main", "db_data"), want: true},
+		{name: "migrations of this app", source: filepath.Join("/home", "app", "nhost", "migrations"), want: true},
+		{name: "data of another app", source: filepath.Join("/home", "other", ".nhost", "main", "db_data")},
+		{name: "app with a similar name", source: filepath.Join("/home", "app-old", ".nhost", "db_data")},
+		{name: "no mounts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var item types.Container
+			if tt.source != "" {
+				item.Mounts = []types.MountPoint{{Type: "bind", Source: tt.source}}
+			}
+
+			if got := mountsApp(item); got != tt.want {
+				t.Errorf("mountsApp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,14 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	client "github.com/docker/docker/client"
+	"github.com/nhost/cli/hasura"
 	"github.com/nhost/cli/nhost"
 	"github.com/nhost/cli/util"
 	"github.com/nhost/cli/watcher"
@@ -90,7 +90,7 @@ func (e *Environment) Init() error {
 		return err
 	}
 
-	//  get running containers with prefix "nhost_"
+	//  get containers labelled with this app's project
 	containers, err := e.GetContainers()
 	if err != nil {
 		status.Errorln(util.ErrServicesNotFound)
@@ -113,10 +113,10 @@ func (e *Environment) Init() error {
 	if util.PathExists(nhost.GIT_DIR) {
 
 		//  Initialize watcher for post-checkout branch changes
-		e.Watcher.Register(filepath.Join(nhost.GIT_DIR, "HEAD"), e.restartAfterCheckout)
+		e.Watcher.Register(nhost.GetHEAD(), e.restartAfterCheckout)
 
 		//  Initialize watcher for post-merge commit changes
-		head := getBranchHEAD(filepath.Join(nhost.GIT_DIR, "refs", "remotes", nhost.REMOTE))
+		head := getBranchHEAD(filepath.Join(nhost.CommonGitDir(), "refs", "remotes", nhost.REMOTE))
 		if head != "" {
 			e.Watcher.Register(head, e.restartMigrations)
 		}
@@ -159,25 +159,17 @@ func (e *Environment) Prepare() error {
 	//  then Hasura must be auto-applying migrations
 	//  hence, manually applying migrations doesn't make sense
 
-	//  create migrations
-	files, _ := os.ReadDir(nhost.MIGRATIONS_DIR)
-	if len(files) > 0 {
+	//  apply migrations, which haven't been applied yet
+	log.Debug("Applying migrations")
 
-		log.Debug("Applying migrations")
-
-		execute := exec.CommandContext(e.ExecutionContext, e.Hasura.CLI)
-		execute.Dir = nhost.NHOST_DIR
-
-		cmdArgs := []string{e.Hasura.CLI, "migrate", "apply"}
-		cmdArgs = append(cmdArgs, e.Hasura.CommonOptions...)
-		execute.Args = cmdArgs
+	migrations, err := e.Hasura.ApplyMigrations(nhost.DATABASE)
+	if err != nil {
+		status.Errorln(fmt.Sprintf("Failed to apply migrations: %v", err))
+		return err
+	}
 
-		output, err := execute.CombinedOutput()
-		if err != nil {
-			log.Debug(string(output))
-			status.Errorln("Failed to apply migrations")
-			return err
-		}
+	for _, item := range migrations {
+		log.WithField("component", item.Name).Debug("Applied migration ", item.Version)
 	}
 
 	metaFiles, err := os.ReadDir(nhost.METADATA_DIR)
@@ -186,67 +178,35 @@ func (e *Environment) Prepare() error {
 	}
 
 	if len(metaFiles) == 0 {
-
-		// Export metadata
-		log.Debug("Exporting metadata")
-
-		execute := exec.CommandContext(e.ExecutionContext, e.Hasura.CLI)
-		execute.Dir = nhost.NHOST_DIR
-
-		cmdArgs := []string{e.Hasura.CLI, "metadata", "export"}
-		cmdArgs = append(cmdArgs, e.Hasura.CommonOptionsWithoutDB...)
-		execute.Args = cmdArgs
-
-		output, err := execute.CombinedOutput()
-		if err != nil {
-			log.Debug(string(output))
+		if err := e.exportMetadata(); err != nil {
 			status.Errorln("Failed to export metadata")
 			return err
 		}
 	}
 
 	// apply metadata
-	log.Debug("Applying metadata")
-
-	execute := exec.CommandContext(e.ExecutionContext, e.Hasura.CLI)
-	execute.Dir = nhost.NHOST_DIR
+	if err := e.applyMetadata(); err != nil {
 
-	cmdArgs := []string{e.Hasura.CLI, "metadata", "apply"}
-	cmdArgs = append(cmdArgs, e.Hasura.CommonOptionsWithoutDB...)
-	execute.Args = cmdArgs
+		log.Debug(err)
+		status.Errorln(fmt.Sprintf("Failed to apply metadata: %v", err))
 
-	output, err := execute.CombinedOutput()
-	if err != nil {
-		log.Debug(string(output))
-		status.Errorln("Failed to apply metadata")
-		return err
-	}
+		var failure *hasura.MetadataError
+		if errors.As(err, &failure) && len(failure.Inconsistencies) > 0 {
+			fmt.Println(hasura.InconsistencyReport(failure.Inconsistencies))
+		}
 
-	// Reload Hasura Auth and Hasura Storage to re-apply their metadata (and migrations)
-	for _, x := range []string{"auth", "storage"} {
-		log.Debugf("Restarting %s container", x)
-		//	Restart the container
-		if err := e.Docker.ContainerRestart(e.Context, e.Config.Services[x].ID, nil); err != nil {
+		if !e.AllowInconsistent {
 			return err
 		}
-	}
 
-	//	Run helath-check again to wait for restarted containers to become active
-	if err := e.HealthCheck(e.ExecutionContext); err != nil {
-		return err
+		//  Services keep running with the previous metadata,
+		//  and local metadata isn't overwritten by exporting it
+		status.Infoln("Fix it, and apply it again with `nhost metadata apply`")
+		return nil
 	}
 
 	// Exporting metadata to keep local metadata in sync.
-	log.Debug("Exporting metadata")
-	execute = exec.CommandContext(e.ExecutionContext, e.Hasura.CLI)
-	execute.Dir = nhost.NHOST_DIR
-	cmdArgs = []string{e.Hasura.CLI, "metadata", "export"}
-	cmdArgs = append(cmdArgs, e.Hasura.CommonOptionsWithoutDB...)
-	execute.Args = cmdArgs
-
-	output, err = execute.CombinedOutput()
-	if err != nil {
-		log.Debug(string(output))
+	if err := e.exportMetadata(); err != nil {
 		status.Errorln("Failed to export metadata")
 		return err
 	}
@@ -255,114 +215,147 @@ func (e *Environment) Prepare() error {
 }
 
 //  Runs concurrent healthchecks on all Nhost services,
-//  which have a health check endpoint.
+//  using the probe configured in their `healthcheck` block.
 //
+//  Fails fast if a container crashes, and prints it's last log lines.
 //  Also, supports process cancellation from contexts.
 func (e *Environment) HealthCheck(ctx context.Context) error {
 
 	e.UpdateState(HealthChecks)
 	log.Debug("Starting health check")
 
-	var err error
-	var health_waiter sync.WaitGroup
+	var (
+		failed        []string
+		mutex         sync.Mutex
+		health_waiter sync.WaitGroup
+	)
+
 	for _, service := range e.Config.Services {
-		if service.HealthEndpoint != "" {
-			status.Update(1)
-			health_waiter.Add(1)
-			go func(service *nhost.Service) {
-
-				for counter := 1; counter <= 240; counter++ {
-					select {
-					case <-ctx.Done():
-						log.WithFields(logrus.Fields{
-							"type":      "service",
-							"container": service.Name,
-						}).Debug("Health check cancelled")
-						return
-					default:
-						if healthy := service.Healthz(); healthy {
-							status.Increment(1)
-							log.WithFields(logrus.Fields{
-								"type":      "service",
-								"container": service.Name,
-							}).Debug("Health check successful")
-
-							//  Activate the service
-							service.Activate()
-
-							health_waiter.Done()
-
-							return
-						}
-						time.Sleep(1 * time.Second)
-						log.WithFields(logrus.Fields{
-							"type":      "container",
-							"component": service.Name,
-						}).Debugf("Health check attempt #%v unsuccessful", counter)
-					}
-				}
-
-				status.Error("Health check failed for " + service.Name)
-				err = errors.New("health check of at least 1 service has failed")
-
-			}(service)
-		} else {
-
-			//
-			//	If any service doesn't have a health check endpoint,
-			//	then, by default, declare it active.
-			//
-			//	This is being done to prevent the enviroment from failing activation checks.
-			//	Because we are not performing health checks on postgres and mailhog,
-			//	they end up making the entire environment fail activation check.
-			service.Active = true
-		}
+		health_waiter.Add(1)
+		go func(service *nhost.Service) {
+			defer health_waiter.Done()
+
+			//	Cancellation is not a failure of the service
+			if err := e.checkHealth(ctx, service); err != nil && ctx.Err() == nil {
+				mutex.Lock()
+				failed = append(failed, service.Name)
+				mutex.Unlock()
+			}
+		}(service)
 	}
 
 	//  wait for all healthchecks to pass
 	health_waiter.Wait()
-	return err
+
+	if len(failed) > 0 {
+		return fmt.Errorf("health check failed for: %s", strings.Join(failed, ", "))
+	}
+
+	return ctx.Err()
 }
 
-func (e *Environment) Seed(path string) error {
+//	Waits for the service to become healthy, and activates it.
+//	Failures are reported along with the last log lines of the service.
+func (e *Environment) checkHealth(ctx context.Context, service *nhost.Service) error {
+
+	//
+	//	If any service doesn't have a health check,
+	//	or it's a remote service without a local container, then,
+	//	by default, declare it active.
+	//
+	//	Probes of services without containers would only reach local ports,
+	//	where nothing listens, like mailhog's with a custom SMTP server.
+	//
+	//	This is being done to prevent the enviroment from failing activation checks.
+	if service.HealthCheck == nil || service.NoContainer {
+		service.Activate()
+		return nil
+	}
 
-	seed_files, err := ioutil.ReadDir(path)
-	if err != nil {
+	status.Update(1)
+
+	if err := e.waitForHealth(ctx, service); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		log.WithField("component", service.Name).Debug(err)
+		e.reportUnhealthy(service, err)
 		return err
 	}
 
-	if len(seed_files) > 0 {
-		log.Debug("Applying seeds")
-	}
+	status.Increment(1)
+	log.WithFields(logrus.Fields{
+		"type":      "service",
+		"container": service.Name,
+	}).Debug("Health check successful")
+
+	//  Activate the service
+	service.Activate()
+	return nil
+}
 
-	//  if there are more seeds than just enum tables,
-	//  apply them too
-	for _, item := range seed_files {
+//	Probes the service until it's healthy, retries run out, or it's container crashes.
+func (e *Environment) waitForHealth(ctx context.Context, service *nhost.Service) error {
 
-		//  read seed file
-		data, err := ioutil.ReadFile(filepath.Join(path, item.Name()))
-		if err != nil {
-			status.Errorln("Failed to open:" + item.Name())
-			return err
+	var err error
+	check := service.HealthCheck
+
+	for counter := 1; counter <= check.GetRetries(); counter++ {
+
+		if err = service.Probe(e.Docker, ctx); err == nil {
+			return nil
 		}
 
-		//  apply seed data
-		if err := e.Hasura.Seed(string(data)); err != nil {
-			status.Errorln("Failed to apply:" + item.Name())
-			return err
+		log.WithFields(logrus.Fields{
+			"type":      "container",
+			"component": service.Name,
+		}).Debugf("Health check attempt #%v unsuccessful", counter)
+
+		//	No point in waiting for a container which has crashed
+		if crashed := service.Crashed(e.Docker, ctx); crashed != nil {
+			return crashed
+		}
+
+		select {
+		case <-ctx.Done():
+			log.WithFields(logrus.Fields{
+				"type":      "service",
+				"container": service.Name,
+			}).Debug("Health check cancelled")
+			return ctx.Err()
+		case <-time.After(check.GetInterval()):
 		}
-		/*
-			cmdArgs = []string{hasuraCLI, "seed", "apply", "--database-name", "default"}
-			cmdArgs = append(cmdArgs, commandConfiguration...)
-			execute.Args = cmdArgs
-
-			if err = execute.Run(); err != nil {
-				status.Errorln("Failed to apply seeds")
-				return err
-			}
-		*/
 	}
-	return nil
+
+	return err
+}
+
+var reportMutex sync.Mutex
+
+//	Prints the reason of failure, along with last log lines of the service
+func (e *Environment) reportUnhealthy(service *nhost.Service, err error) {
+
+	//	Don't mix up the logs of services failing concurrently
+	reportMutex.Lock()
+	defer reportMutex.Unlock()
+
+	status.Errorln(fmt.Sprintf("Health check failed for %s: %v", service.Name, err))
+
+	if service.NoContainer || service.ID == "" {
+		return
+	}
+
+	logs, tailErr := service.Tail(e.Docker, e.Context, 20)
+	if tailErr != nil {
+		log.WithField("component", service.Name).Debug(tailErr)
+		return
+	}
+
+	if logs = strings.TrimSpace(logs); logs != "" {
+		fmt.Println(logs)
+		fmt.Println()
+	}
 }
 
 func (e *Environment) Cleanup() {
@@ -382,7 +375,7 @@ func (e *Environment) Cleanup() {
 		//	Pass the parent context of the environment,
 		//	because this is the final cleanup procedure
 		//	and we are going to cancel this context shortly after
-		if err := e.Shutdown(true, e.Context); err != nil {
+		if err := e.Shutdown(!e.KeepContainers, e.Context); err != nil {
 			log.Debug(err)
 			status.Error("Failed to stop running services")
 		}
@@ -0,0 +1,28 @@
+package environment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nhost/cli/nhost"
+)
+
+func TestCheckHealth(t *testing.T) {
+
+	//  Mailhog isn't launched with a custom SMTP server, so nothing listens on its port
+	service := &nhost.Service{
+		Name:        "mailhog",
+		Address:     "http://localhost:1",
+		NoContainer: true,
+		HealthCheck: &nhost.HealthCheck{HTTP: "/"},
+	}
+
+	var environment Environment
+	if err := environment.checkHealth(context.Background(), service); err != nil {
+		t.Errorf("checkHealth() error = %v, want services without containers to be skipped", err)
+	}
+
+	if !service.Active {
+		t.Error("checkHealth() didn't activate the service")
+	}
+}
@@ -40,21 +40,18 @@ func (e *Environment) Execute() error {
 		return err
 	}
 
-	//	Create and start the containers
+	//	Remove the containers which were created with a different configuration
+	if err := e.RecreateChanged(); err != nil {
+		return err
+	}
+
+	//	Create and start the containers, in order of their dependencies.
+	//	We are passing execution context, and not parent context,
+	//	because if this execution is cancelled in between,
+	//	we want docker to abort this procedure.
 	status.Set("Starting services")
-	for _, item := range e.Config.Services {
-
-		//	Only those services which have a container configuration
-		//	This is being done to exclude FUNCTIONS
-		if item.Config != nil {
-
-			//	We are passing execution context, and not parent context,
-			//	because if this execution is cancelled in between,
-			//	we want docker to abort this procedure.
-			if err := item.Run(e.Docker, e.ExecutionContext, e.Network); err != nil {
-				return err
-			}
-		}
+	if err := e.Start(e.ExecutionContext); err != nil {
+		return err
 	}
 
 	//
@@ -69,11 +66,6 @@ func (e *Environment) Execute() error {
 	//	Wrap fetched containers as services in the environment
 	_ = e.WrapContainersAsServices(containers)
 
-	//	status.Info("Running a quick health check on services")
-	if err := e.HealthCheck(e.ExecutionContext); err != nil {
-		return err
-	}
-
 	e.UpdateState(Executing)
 	//	e.Status.Set("Preparing your data")
 
@@ -99,8 +91,8 @@ func (e *Environment) Execute() error {
 	//
 	//  Apply Seeds if required
 	//
-	if firstRun && util.PathExists(filepath.Join(nhost.SEEDS_DIR, nhost.DATABASE)) {
-		if err = e.Seed(filepath.Join(nhost.SEEDS_DIR, nhost.DATABASE)); err != nil {
+	if firstRun && util.PathExists(nhost.SeedsDir()) {
+		if err = e.Seed(nhost.SeedsDir()); err != nil {
 			log.Debug(err)
 			e.Cleanup()
 		}
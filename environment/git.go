@@ -3,6 +3,7 @@ package environment
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"path/filepath"
 
@@ -130,11 +131,16 @@ func (e *Environment) restartAfterCheckout() error {
 
 	status.Warnln("We're recreating your environment accordingly. Give us a moment!")
 
+	//  Branch checked out before, to copy it's data if configured
+	previous, _ := filepath.Rel(filepath.Join(util.WORKING_DIR, ".nhost"), nhost.DOT_NHOST)
+
 	//  update DOT_NHOST directory
 	nhost.DOT_NHOST, _ = nhost.GetDotNhost()
 
+	e.InheritData(filepath.ToSlash(previous))
+
 	//  register new branch HEAD for the watcher
-	head := getBranchHEAD(filepath.Join(nhost.GIT_DIR, "refs", "remotes", nhost.REMOTE))
+	head := getBranchHEAD(filepath.Join(nhost.CommonGitDir(), "refs", "remotes", nhost.REMOTE))
 	if head != "" {
 
 		if !e.Watcher.Registered(head) {
@@ -151,6 +157,34 @@ func (e *Environment) restartAfterCheckout() error {
 		return err
 	}
 
+	//  Reuse the ports of services on next runs of the new branch
+	if err := e.Config.SavePorts(); err != nil {
+		log.WithField("path", nhost.PortsPath()).Debug(err)
+	}
+
 	status.Info("Done! Please continue with your work.")
 	return nil
 }
+
+//  Copies the data of parent branch, as configured in config.yaml,
+//  into the current branch, if it has never run before.
+//
+//  It must be called before anything is written into the data directory of the branch.
+func (e *Environment) InheritData(previous string) {
+
+	branch := nhost.GetBranch()
+	if nhost.HasData(branch) {
+		return
+	}
+
+	parent := e.Config.ParentBranch(branch, previous)
+	if parent == "" {
+		return
+	}
+
+	status.Info(fmt.Sprintf("Copying data of branch %s into %s", parent, branch))
+	if err := nhost.CopyBranchData(e.DataRunner(), parent, branch); err != nil {
+		log.Debug(err)
+		status.Errorln(fmt.Sprintf("Failed to copy data of branch %s, starting with empty data", parent))
+	}
+}
@@ -0,0 +1,260 @@
+package environment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/nhost/cli/util"
+)
+
+const (
+
+	//  maximum number of images pulled at the same time
+	maxParallelPulls = 3
+
+	//  number of attempts for every image, before giving up on it
+	pullAttempts = 3
+)
+
+//  Aggregated error of all images which failed to be pulled
+type PullError struct {
+	Failures map[string]error
+}
+
+func (e *PullError) Error() string {
+
+	var lines []string
+	for _, image := range e.Images() {
+		lines = append(lines, fmt.Sprintf("%s: %v", image, e.Failures[image]))
+	}
+
+	return fmt.Sprintf("failed to pull %d image(s): %s", len(lines), strings.Join(lines, "; "))
+}
+
+//  Returns the images which failed to be pulled, sorted by name
+func (e *PullError) Images() []string {
+
+	var response []string
+	for image := range e.Failures {
+		response = append(response, image)
+	}
+	sort.Strings(response)
+
+	return response
+}
+
+//  Single message from the JSON stream returned by image pulls
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	Error          string `json:"error"`
+	ProgressDetail struct {
+		Current int `json:"current"`
+		Total   int `json:"total"`
+	} `json:"progressDetail"`
+}
+
+//  Tracks download progress of every layer, across all images being pulled,
+//  and renders the overall progress through the status writer.
+type pullProgress struct {
+	sync.Mutex
+	layers map[string][2]int
+}
+
+func (p *pullProgress) update(image string, message pullMessage) {
+
+	if message.ID == "" || message.ProgressDetail.Total == 0 {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.layers[image+"@"+message.ID] = [2]int{message.ProgressDetail.Current, message.ProgressDetail.Total}
+
+	var current, total int
+	for _, item := range p.layers {
+		current += item[0]
+		total += item[1]
+	}
+
+	status.Value = current
+	status.Total = total
+	status.Print()
+}
+
+//  Forgets the layers of the image, before it's pulled again,
+//  so that the progress of failed attempts isn't counted twice
+func (p *pullProgress) reset(image string) {
+
+	p.Lock()
+	defer p.Unlock()
+
+	for key := range p.layers {
+		if strings.HasPrefix(key, image+"@") {
+			delete(p.layers, key)
+		}
+	}
+}
+
+//
+//  Validates whether all required images exist,
+//  and downloads any one which doesn't.
+//
+func (e *Environment) CheckImages() error {
+
+	log.Debug("Checking for required Nhost container images")
+
+	//  check if a required image already exists
+	//  if it doesn't -> then pull it
+
+	var requiredImages []string
+	for _, service := range e.Config.Services {
+
+		//  only those services which actually have an image
+		//  this check is being added to exclude NHOST_FUNCTIONS
+		if service.Image != "" {
+			image := fmt.Sprintf("%s:%v", service.Image, service.Version)
+			if !util.Contains(requiredImages, image) {
+				requiredImages = append(requiredImages, image)
+			}
+		}
+	}
+
+	availableImages, err := e.Docker.ImageList(e.Context, types.ImageListOptions{All: true})
+	if err != nil {
+		return err
+	}
+
+	var missingImages []string
+	for _, requiredImage := range requiredImages {
+		available := false
+		for _, image := range availableImages {
+
+			//  check wether the image is available or not.
+			//  Docker lists Docker Hub images without their registry host.
+			if util.Contains(image.RepoTags, requiredImage) || util.Contains(image.RepoTags, familiarName(requiredImage)) {
+				available = true
+			}
+		}
+
+		if !available {
+			missingImages = append(missingImages, requiredImage)
+		}
+	}
+
+	if len(missingImages) == 0 {
+		return nil
+	}
+
+	status.Set(fmt.Sprintf("Pulling %d container image(s)", len(missingImages)))
+
+	var (
+		image_waiter sync.WaitGroup
+		mutex        sync.Mutex
+		failures     = make(map[string]error)
+		progress     = pullProgress{layers: make(map[string][2]int)}
+
+		//  bounds the number of concurrent pulls
+		slots = make(chan struct{}, maxParallelPulls)
+	)
+
+	for _, image := range missingImages {
+		image_waiter.Add(1)
+		go func(image string) {
+			defer image_waiter.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			if err := e.pullImage(e.ExecutionContext, image, &progress); err != nil {
+				log.WithField("component", image).Debug(err)
+				mutex.Lock()
+				failures[image] = err
+				mutex.Unlock()
+			}
+		}(image)
+	}
+
+	image_waiter.Wait()
+	status.Reset()
+
+	if len(failures) > 0 {
+		response := &PullError{Failures: failures}
+
+		status.Errorln(fmt.Sprintf("Failed to pull %d container image(s)", len(failures)))
+		for _, image := range response.Images() {
+			fmt.Printf("  %s: %v\n", image, failures[image])
+		}
+		status.Infoln("Pull them manually with `docker image pull <image>` and restart `nhost dev`")
+		return response
+	}
+
+	return nil
+}
+
+//  Pulls the image through Docker Engine API,
+//  retrying with an increasing backoff on failure.
+func (e *Environment) pullImage(ctx context.Context, image string, progress *pullProgress) error {
+
+	var err error
+
+	for attempt := 1; attempt <= pullAttempts; attempt++ {
+
+		log.WithField("component", image).Debugf("Pulling container image, attempt #%d", attempt)
+
+		progress.reset(image)
+		if err = e.pull(ctx, image, progress); err == nil {
+			return nil
+		}
+
+		if attempt == pullAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt*2) * time.Second):
+		}
+	}
+
+	return err
+}
+
+func (e *Environment) pull(ctx context.Context, image string, progress *pullProgress) error {
+
+	out, err := e.Docker.ImagePull(ctx, image, types.ImagePullOptions{
+		RegistryAuth: registryAuth(image),
+	})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	//  Pull failures are reported inside the stream,
+	//  and not as an error of the API call
+	decoder := json.NewDecoder(out)
+	for {
+		var message pullMessage
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if message.Error != "" {
+			return fmt.Errorf("%s", message.Error)
+		}
+
+		progress.update(image, message)
+	}
+}
@@ -0,0 +1,44 @@
+package environment
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPullError(t *testing.T) {
+
+	err := &PullError{Failures: map[string]error{
+		"postgres:12":      errors.New("timeout"),
+		"hasura/auth:0.6":  errors.New("denied"),
+		"minio/minio:2021": errors.New("timeout"),
+	}}
+
+	want := "failed to pull 3 image(s): hasura/auth:0.6: denied; minio/minio:2021: timeout; postgres:12: timeout"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+}
+
+func TestPullProgress(t *testing.T) {
+
+	progress := pullProgress{layers: make(map[string][2]int)}
+
+	message := pullMessage{ID: "layer"}
+	message.ProgressDetail.Current, message.ProgressDetail.Total = 50, 100
+	progress.update("postgres:12", message)
+	progress.update("minio/minio:2021", message)
+
+	//  A retried pull starts over, without the layers of the failed attempt
+	progress.reset("postgres:12")
+	message.ID = "other"
+	message.ProgressDetail.Current = 10
+	progress.update("postgres:12", message)
+
+	if len(progress.layers) != 2 || progress.layers["postgres:12@other"] != [2]int{10, 100} {
+		t.Errorf("reset() kept layers %v, want only the ones of the new attempt", progress.layers)
+	}
+
+	if status.Value > status.Total {
+		t.Errorf("update() progress = %d/%d, want at most 100%%", status.Value, status.Total)
+	}
+}
@@ -0,0 +1,61 @@
+package environment
+
+import (
+	"fmt"
+
+	"github.com/nhost/cli/hasura"
+	"github.com/nhost/cli/nhost"
+)
+
+//	Applies the local metadata. If the environment allows inconsistencies,
+//	inconsistent objects are skipped instead of failing,
+//	so that the app remains usable while they are being fixed.
+//	Inconsistent objects are reported, and recorded in the environment.
+func (e *Environment) applyMetadata() error {
+
+	log.Debug("Applying metadata")
+
+	metadata, err := hasura.LoadMetadata(nhost.METADATA_DIR)
+	if err != nil {
+		return err
+	}
+
+	if _, err := e.Hasura.ApplyMetadata(metadata, e.AllowInconsistent); err != nil {
+		return err
+	}
+
+	response, err := e.Hasura.GetInconsistentMetadata()
+	if err != nil {
+		return err
+	}
+
+	e.Inconsistencies = response.InconsistentObjects
+
+	if !response.IsConsistent && !e.AllowInconsistent {
+		return &hasura.MetadataError{
+			Response:        hasura.Response{Error: "metadata is inconsistent"},
+			Inconsistencies: response.InconsistentObjects,
+		}
+	}
+
+	if !response.IsConsistent {
+		status.Warnln("Skipped inconsistent objects of your metadata:")
+		fmt.Println(hasura.InconsistencyReport(response.InconsistentObjects))
+		status.Infoln("Fix them, and run `nhost metadata apply`, or drop them with `nhost metadata inconsistencies --drop`")
+	}
+
+	return nil
+}
+
+//	Exports the metadata of the running app to the local metadata directory
+func (e *Environment) exportMetadata() error {
+
+	log.Debug("Exporting metadata")
+
+	metadata, err := e.Hasura.FetchMetadata()
+	if err != nil {
+		return err
+	}
+
+	return metadata.Write(nhost.METADATA_DIR)
+}
@@ -0,0 +1,101 @@
+package environment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nhost/cli/hasura"
+	"github.com/nhost/cli/nhost"
+)
+
+func TestApplyMetadata(t *testing.T) {
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"version.yaml":                               "version: 3\n",
+		"databases/databases.yaml":                   "- name: default\n  kind: postgres\n  tables: \"!include default/tables/tables.yaml\"\n",
+		"databases/default/tables/tables.yaml":       "- \"!include public_todos.yaml\"\n",
+		"databases/default/tables/public_todos.yaml": "table:\n  name: todos\n  schema: public\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous := nhost.METADATA_DIR
+	nhost.METADATA_DIR, nhost.DATABASE = dir, "default"
+	defer func() { nhost.METADATA_DIR = previous }()
+
+	inconsistency := `{"type": "table", "reason": "no such table/view exists in source: \"public.todos\"", "definition": {"schema": "public", "name": "todos"}}`
+
+	var replaced struct {
+		Version uint `json:"version"`
+		Args    struct {
+			AllowInconsistentMetadata bool                   `json:"allow_inconsistent_metadata"`
+			Metadata                  map[string]interface{} `json:"metadata"`
+		} `json:"args"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body hasura.RequestBody
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+
+		switch body.Type {
+		case "replace_metadata":
+			json.Unmarshal(data, &replaced)
+			fmt.Fprintf(w, `{"is_consistent": false, "inconsistent_objects": [%s]}`, inconsistency)
+		case "get_inconsistent_metadata":
+			fmt.Fprintf(w, `{"is_consistent": false, "inconsistent_objects": [%s]}`, inconsistency)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "unexpected request", "code": "bad-request"}`)
+		}
+	}))
+	defer server.Close()
+
+	//  Only `nhost dev` keeps running with inconsistent metadata
+	e := Environment{Hasura: &hasura.Client{Endpoint: server.URL, Client: server.Client()}}
+
+	var failure *hasura.MetadataError
+	if err := e.applyMetadata(); !errors.As(err, &failure) || len(failure.Inconsistencies) != 1 {
+		t.Errorf("applyMetadata() error = %v, want the inconsistent public.todos", err)
+	}
+
+	if replaced.Args.AllowInconsistentMetadata {
+		t.Errorf("applyMetadata() allowed inconsistent metadata")
+	}
+
+	e.AllowInconsistent = true
+	if err := e.applyMetadata(); err != nil {
+		t.Fatalf("applyMetadata() error = %v", err)
+	}
+
+	if replaced.Version != 2 || !replaced.Args.AllowInconsistentMetadata {
+		t.Errorf("applyMetadata() didn't allow inconsistent metadata")
+	}
+
+	sources, _ := replaced.Args.Metadata["sources"].([]interface{})
+	if len(sources) != 1 {
+		t.Fatalf("applied sources = %v, want the default one", replaced.Args.Metadata["sources"])
+	}
+
+	if tables, _ := sources[0].(map[string]interface{})["tables"].([]interface{}); len(tables) != 1 {
+		t.Errorf("applied tables = %v, want the included public.todos", tables)
+	}
+
+	if len(e.Inconsistencies) != 1 || e.Inconsistencies[0].File() != "databases/default/tables/public_todos.yaml" {
+		t.Errorf("Inconsistencies = %v, want public.todos", e.Inconsistencies)
+	}
+}
@@ -0,0 +1,169 @@
+package environment
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+//  Docker Hub's key inside docker config files
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+//  Subset of docker's config.json used for registry authentication
+type dockerConfig struct {
+	Auths       map[string]types.AuthConfig `json:"auths"`
+	CredsStore  string                      `json:"credsStore,omitempty"`
+	CredHelpers map[string]string           `json:"credHelpers,omitempty"`
+}
+
+//  Returns the registry host of an image reference.
+//  Follows docker's normalization rules, where images without a host belong to Docker Hub.
+func registryHost(image string) string {
+
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		if parts[0] == "index.docker.io" {
+			return "docker.io"
+		}
+		return parts[0]
+	}
+
+	return "docker.io"
+}
+
+//  Returns the image reference the way docker lists it locally.
+//  Example: docker.io/library/postgres:12 -> postgres:12
+func familiarName(image string) string {
+
+	for _, prefix := range []string{"docker.io/", "index.docker.io/"} {
+		if strings.HasPrefix(image, prefix) {
+			return strings.TrimPrefix(strings.TrimPrefix(image, prefix), "library/")
+		}
+	}
+
+	return image
+}
+
+//  Loads docker's config.json from $DOCKER_CONFIG or $HOME/.docker
+func loadDockerConfig() (dockerConfig, error) {
+
+	var response dockerConfig
+
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return response, err
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return response, err
+	}
+
+	err = json.Unmarshal(data, &response)
+	return response, err
+}
+
+//  Returns the base64 encoded registry credentials for the image,
+//  from credential helpers or saved auths of docker's config.json.
+//  Returns a blank string for anonymous pulls.
+func registryAuth(image string) string {
+
+	config, err := loadDockerConfig()
+	if err != nil {
+		log.Debug(err)
+		return ""
+	}
+
+	host := registryHost(image)
+	key := host
+	if host == "docker.io" {
+		key = dockerHubAuthKey
+	}
+
+	var auth types.AuthConfig
+
+	helper := config.CredsStore
+	if item, ok := config.CredHelpers[host]; ok {
+		helper = item
+	}
+
+	if helper != "" {
+		auth, err = credentialsFromHelper(helper, key)
+		if err != nil {
+			log.WithField("component", helper).Debug(err)
+		}
+	}
+
+	if auth.Username == "" && auth.IdentityToken == "" {
+		saved, ok := config.Auths[key]
+		if !ok {
+			return ""
+		}
+
+		//  "auth" field is the base64 encoded "username:password"
+		if saved.Auth != "" {
+			if decoded, err := base64.StdEncoding.DecodeString(saved.Auth); err == nil {
+				pair := strings.SplitN(string(decoded), ":", 2)
+				if len(pair) == 2 {
+					saved.Username, saved.Password = pair[0], pair[1]
+				}
+			}
+			saved.Auth = ""
+		}
+
+		auth = saved
+	}
+
+	auth.ServerAddress = key
+
+	payload, err := json.Marshal(auth)
+	if err != nil {
+		return ""
+	}
+
+	return base64.URLEncoding.EncodeToString(payload)
+}
+
+//  Fetches credentials from a docker credential helper, like `docker-credential-desktop`
+func credentialsFromHelper(helper, server string) (types.AuthConfig, error) {
+
+	var response types.AuthConfig
+
+	execute := exec.Command("docker-credential-"+helper, "get")
+	execute.Stdin = strings.NewReader(server)
+
+	output, err := execute.Output()
+	if err != nil {
+		return response, err
+	}
+
+	var credentials struct {
+		Username string
+		Secret   string
+	}
+
+	if err := json.NewDecoder(bytes.NewReader(output)).Decode(&credentials); err != nil {
+		return response, err
+	}
+
+	//  Helpers return "<token>" as username for identity tokens
+	if credentials.Username == "<token>" {
+		response.IdentityToken = credentials.Secret
+	} else {
+		response.Username = credentials.Username
+		response.Password = credentials.Secret
+	}
+
+	return response, nil
+}
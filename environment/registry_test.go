@@ -0,0 +1,34 @@
+package environment
+
+import "testing"
+
+func TestRegistryHost(t *testing.T) {
+
+	for image, want := range map[string]string{
+		"postgres:12":                        "docker.io",
+		"hasura/graphql-engine:v2.2.0":       "docker.io",
+		"docker.io/nhost/hasura-auth:0.6.3":  "docker.io",
+		"index.docker.io/nhost/postgres:12":  "docker.io",
+		"ghcr.io/nhost/hasura-storage:0.2.2": "ghcr.io",
+		"localhost/my-worker:latest":         "localhost",
+		"registry.local:5000/worker:latest":  "registry.local:5000",
+	} {
+		if got := registryHost(image); got != want {
+			t.Errorf("registryHost(%v) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestFamiliarName(t *testing.T) {
+
+	for image, want := range map[string]string{
+		"docker.io/library/postgres:12":     "postgres:12",
+		"docker.io/nhost/hasura-auth:0.6.3": "nhost/hasura-auth:0.6.3",
+		"minio/minio:RELEASE":               "minio/minio:RELEASE",
+		"ghcr.io/nhost/storage:0.2.2":       "ghcr.io/nhost/storage:0.2.2",
+	} {
+		if got := familiarName(image); got != want {
+			t.Errorf("familiarName(%v) = %v, want %v", image, got, want)
+		}
+	}
+}
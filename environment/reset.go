@@ -0,0 +1,43 @@
+package environment
+
+import (
+	"os"
+
+	"github.com/nhost/cli/nhost"
+)
+
+//	Resets the database of the running environment to a clean schema.
+//
+//	Drops all application schemas, and then re-applies migrations, metadata and seeds.
+//	Schemas of auth and storage are left to their services.
+func (e *Environment) Reset() error {
+
+	log.Debug("Resetting database")
+
+	schemas, err := e.Hasura.GetSchemas()
+	if err != nil {
+		return err
+	}
+
+	if err := e.Hasura.ResetSchemas(schemas); err != nil {
+		return err
+	}
+
+	//	Forget the applied migrations, so that all of them are applied again
+	if files, _ := os.ReadDir(nhost.MIGRATIONS_DIR); len(files) > 0 {
+		if err := e.Hasura.ClearMigration(nhost.DATABASE); err != nil {
+			return err
+		}
+	}
+
+	if err := e.Prepare(); err != nil {
+		return err
+	}
+
+	//	Forget the applied seeds, since their data has been dropped
+	if err := e.Hasura.ClearSeeds(); err != nil {
+		return err
+	}
+
+	return e.Seed(nhost.SeedsDir())
+}
@@ -0,0 +1,118 @@
+package environment
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nhost/cli/nhost"
+)
+
+//	Applies the seeds in the directory, which haven't been applied yet.
+func (e *Environment) Seed(path string) error {
+
+	_, changed, err := e.ApplySeeds(path, nil, false)
+	if err != nil {
+		status.Errorln("Failed to apply seeds")
+		return err
+	}
+
+	for _, item := range changed {
+		status.Warnln(fmt.Sprintf("Seed %s has changed since it was applied, re-apply it with `nhost seed apply --file %s --reset`", item.Name, item.Name))
+	}
+
+	return nil
+}
+
+//	Applies the seeds in the directory in order of their names,
+//	every one in it's own transaction, and records their checksums.
+//
+//	Seeds which have already been applied are skipped.
+//	Changed ones are only applied again with reset, and otherwise returned as changed.
+//	If names are given, only those seeds are applied, and with reset, even if unchanged.
+func (e *Environment) ApplySeeds(path string, names []string, reset bool) ([]nhost.Seed, []nhost.Seed, error) {
+
+	var applied, changed []nhost.Seed
+
+	seeds, err := nhost.LoadSeeds(path)
+	if err != nil {
+		return applied, changed, err
+	}
+
+	if len(names) > 0 {
+		if seeds, err = selectSeeds(seeds, names); err != nil {
+			return applied, changed, err
+		}
+	}
+
+	if len(seeds) == 0 {
+		return applied, changed, nil
+	}
+
+	log.Debug("Applying seeds")
+
+	recorded, err := e.Hasura.AppliedSeeds()
+	if err != nil {
+		return applied, changed, err
+	}
+
+	for _, item := range seeds {
+
+		if checksum, ok := recorded[item.Name]; ok {
+			if checksum != item.Checksum && !reset {
+				changed = append(changed, item)
+				continue
+			}
+
+			if checksum == item.Checksum && (!reset || len(names) == 0) {
+				continue
+			}
+		}
+
+		if err := e.Hasura.RunSeed(item.Name, item.Checksum, item.SQL); err != nil {
+			return applied, changed, fmt.Errorf("failed to apply seed %s: %w", item.Name, err)
+		}
+
+		applied = append(applied, item)
+	}
+
+	return applied, changed, nil
+}
+
+//	Filters the seeds by their names, or paths, with or without the .sql extension,
+//	keeping them in order of their names.
+func selectSeeds(seeds []nhost.Seed, names []string) ([]nhost.Seed, error) {
+
+	var response []nhost.Seed
+
+	matches := func(item nhost.Seed, name string) bool {
+		name = filepath.Base(name)
+		return item.Name == name || strings.TrimSuffix(item.Name, ".sql") == name
+	}
+
+	for _, name := range names {
+
+		var found bool
+		for _, item := range seeds {
+			if matches(item, name) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return response, fmt.Errorf("no seed named %s found", name)
+		}
+	}
+
+	for _, item := range seeds {
+		for _, name := range names {
+			if matches(item, name) {
+				response = append(response, item)
+				break
+			}
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,104 @@
+package environment
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nhost/cli/hasura"
+	"github.com/nhost/cli/nhost"
+)
+
+func TestApplySeeds(t *testing.T) {
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"2_posts.sql": "INSERT INTO posts VALUES (1);",
+		"1_users.sql": "INSERT INTO users VALUES (1);",
+		"3_tags.sql":  "INSERT INTO tags VALUES (1);",
+		"notes.txt":   "not a seed",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seeds, err := nhost.LoadSeeds(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//  users is unchanged, and posts has changed since it was applied
+	recorded := [][]interface{}{{"name", "checksum"}, {"1_users.sql", seeds[0].Checksum}, {"2_posts.sql", "outdated"}}
+
+	var executed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Args map[string]string `json:"args"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		sql := body.Args["sql"]
+		switch {
+		case strings.HasPrefix(sql, "SELECT name, checksum"):
+			json.NewEncoder(w).Encode(hasura.SQLResult{Type: "TuplesOk", Rows: recorded})
+		case strings.HasPrefix(sql, "INSERT"):
+			executed = append(executed, strings.SplitN(sql, "\n", 2)[0])
+			fallthrough
+		default:
+			fmt.Fprint(w, `{"result_type": "CommandOk", "result": null}`)
+		}
+	}))
+	defer server.Close()
+
+	e := Environment{Hasura: &hasura.Client{Endpoint: server.URL, Client: server.Client()}}
+
+	tests := []struct {
+		name        string
+		names       []string
+		reset       bool
+		wantApplied []string
+		wantChanged []string
+	}{
+		{name: "new seeds", wantApplied: []string{"3_tags.sql"}, wantChanged: []string{"2_posts.sql"}},
+		{name: "reset changed seeds", reset: true, wantApplied: []string{"2_posts.sql", "3_tags.sql"}},
+		{name: "reset selected seeds", names: []string{"1_users", "3_tags.sql"}, reset: true, wantApplied: []string{"1_users.sql", "3_tags.sql"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			executed = nil
+			applied, changed, err := e.ApplySeeds(dir, tt.names, tt.reset)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotApplied, gotChanged []string
+			for _, item := range applied {
+				gotApplied = append(gotApplied, item.Name)
+			}
+			for _, item := range changed {
+				gotChanged = append(gotChanged, item.Name)
+			}
+
+			if !reflect.DeepEqual(gotApplied, tt.wantApplied) || !reflect.DeepEqual(gotChanged, tt.wantChanged) {
+				t.Errorf("ApplySeeds() applied = %v, changed = %v, want %v and %v", gotApplied, gotChanged, tt.wantApplied, tt.wantChanged)
+			}
+
+			if len(executed) != len(tt.wantApplied) {
+				t.Errorf("ApplySeeds() executed %v", executed)
+			}
+		})
+	}
+
+	if _, _, err := e.ApplySeeds(dir, []string{"missing"}, false); err == nil {
+		t.Error("ApplySeeds() accepted an unknown seed")
+	}
+}
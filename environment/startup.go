@@ -0,0 +1,98 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nhost/cli/nhost"
+	"github.com/sirupsen/logrus"
+)
+
+//	Creates and starts the containers of all services, in order of their dependencies.
+//
+//	Services which don't depend on each other are started concurrently,
+//	and every service waits for the health checks of it's dependencies before starting.
+//	Returns as soon as any service fails to start or become healthy.
+func (e *Environment) Start(ctx context.Context) error {
+
+	if err := e.Config.ValidateDependencies(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	//	Closed once the service is healthy
+	ready := make(map[string]chan struct{})
+	for name := range e.Config.Services {
+		ready[name] = make(chan struct{})
+	}
+
+	var (
+		failed []string
+		mutex  sync.Mutex
+		waiter sync.WaitGroup
+	)
+
+	fail := func(name string) {
+		mutex.Lock()
+		failed = append(failed, name)
+		mutex.Unlock()
+
+		//	Don't start the services depending on this one
+		cancel()
+	}
+
+	for name, service := range e.Config.Services {
+		waiter.Add(1)
+		go func(name string, service *nhost.Service) {
+			defer waiter.Done()
+
+			for _, dependency := range e.Config.Dependencies(name) {
+				select {
+				case <-ready[dependency]:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			//	Only those services which have a container configuration
+			//	This is being done to exclude FUNCTIONS
+			if service.Config != nil {
+
+				log.WithFields(logrus.Fields{
+					"type":      "service",
+					"container": service.Name,
+				}).Debug("Dependencies are healthy")
+
+				if err := service.Run(e.Docker, ctx, e.Network); err != nil {
+					if ctx.Err() == nil {
+						log.WithField("component", service.Name).Debug(err)
+						status.Errorln(fmt.Sprintf("Failed to start %s", service.Name))
+						fail(service.Name)
+					}
+					return
+				}
+			}
+
+			if err := e.checkHealth(ctx, service); err != nil {
+				if ctx.Err() == nil {
+					fail(service.Name)
+				}
+				return
+			}
+
+			close(ready[name])
+		}(name, service)
+	}
+
+	waiter.Wait()
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to start: %s", strings.Join(failed, ", "))
+	}
+
+	return ctx.Err()
+}
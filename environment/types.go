@@ -39,5 +39,17 @@ type (
 		Network string
 
 		Watcher *watcher.Watcher
+
+		//  Only stop, and don't remove, the containers on cleanup.
+		//  Used by detached environments, so they can be resumed later.
+		KeepContainers bool
+
+		//  Keep the app running when metadata fails to apply, or has inconsistent objects,
+		//  instead of failing. Used by `nhost dev`, so that they can be fixed while it runs.
+		AllowInconsistent bool
+
+		//  Objects of the metadata which Hasura failed to apply,
+		//  the last time it was applied. The app keeps running without them.
+		Inconsistencies []hasura.InconsistentObject
 	}
 )
@@ -2,6 +2,7 @@ package functions
 
 import (
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"net/http"
@@ -67,6 +68,9 @@ type ServerConfig struct {
 	Log *logrus.Logger
 
 	Mux *http.ServeMux
+
+	//	(Optional) Additional destination of the output of functions.
+	Output io.Writer
 }
 
 //	Intializes and returns a new functions server.
@@ -296,8 +300,8 @@ func (s *Server) FunctionHandler(w http.ResponseWriter, r *http.Request) {
 			Path:   nodeCLI,
 			Env:    envVars,
 			Args:   []string{nodeCLI, f.ServerConfig},
-			Stdout: os.Stdout,
-			Stderr: os.Stderr,
+			Stdout: s.output(os.Stdout),
+			Stderr: s.output(os.Stderr),
 		}
 
 		//	begin the comand execution
@@ -334,3 +338,12 @@ func (s *Server) FunctionHandler(w http.ResponseWriter, r *http.Request) {
 		f.Handler(w, r)
 	}
 }
+
+//	Returns the writer for output of functions,
+//	which includes the additional output destination, if configured.
+func (s *Server) output(standard io.Writer) io.Writer {
+	if s.config.Output == nil {
+		return standard
+	}
+	return io.MultiWriter(standard, s.config.Output)
+}
@@ -0,0 +1,777 @@
+package hasura
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+//  Kinds of custom types, by their keys in metadata,
+//  and the keywords defining them in GraphQL
+var customTypeKinds = []struct{ key, keyword string }{
+	{"input_objects", "input"},
+	{"objects", "type"},
+	{"enums", "enum"},
+	{"scalars", "scalar"},
+}
+
+//  Keys of action definitions, and custom types,
+//  which are saved to actions.graphql instead of actions.yaml
+var (
+	actionSignatureKeys = []string{"type", "arguments", "output_type"}
+	customTypeSDLKeys   = []string{"description", "fields", "values"}
+)
+
+//  Actions and custom types defined in actions.graphql
+type actionsSchema struct {
+
+	//  Definitions of actions, containing only their signatures, by their names
+	actions map[string]yaml.MapSlice
+
+	//  Custom types, by their keys in metadata
+	types map[string][]yaml.MapSlice
+}
+
+//  Loads actions and custom types, by combining actions.yaml and actions.graphql
+func loadActions(dir string) (interface{}, interface{}, error) {
+
+	value, err := readMetadataFile(dir, "actions.yaml")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "actions.graphql"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	schema, err := parseActionsSchema(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("actions.graphql: %w", err)
+	}
+
+	actions := []interface{}{}
+	list, _ := lookup(value, "actions")
+	items, _ := list.([]interface{})
+
+	for _, item := range items {
+		action, ok := item.(yaml.MapSlice)
+		if !ok {
+			return nil, nil, errors.New("actions.yaml: invalid action")
+		}
+
+		name, _ := lookup(action, "name")
+		signature, ok := schema.actions[fmt.Sprint(name)]
+		if !ok {
+			return nil, nil, fmt.Errorf("action %v is not defined in actions.graphql", name)
+		}
+
+		definition, _ := lookup(action, "definition")
+		object, _ := definition.(yaml.MapSlice)
+		for _, item := range signature {
+			object = replace(object, fmt.Sprint(item.Key), item.Value)
+		}
+
+		actions = append(actions, replace(action, "definition", object))
+	}
+
+	//  Custom types are defined in actions.graphql,
+	//  while actions.yaml contains other details, like relationships
+	customTypes := yaml.MapSlice{}
+	empty := true
+	types, _ := lookup(value, "custom_types")
+
+	for _, kind := range customTypeKinds {
+
+		defined := schema.types[kind.key]
+		response := []interface{}{}
+		for _, item := range defined {
+			response = append(response, item)
+		}
+
+		list, _ := lookup(types, kind.key)
+		items, _ := list.([]interface{})
+
+		for _, item := range items {
+			name, _ := lookup(item, "name")
+
+			index := -1
+			for position, definition := range defined {
+				if value, _ := lookup(definition, "name"); fmt.Sprint(value) == fmt.Sprint(name) {
+					index = position
+				}
+			}
+
+			if index < 0 {
+				return nil, nil, fmt.Errorf("custom type %v is not defined in actions.graphql", name)
+			}
+
+			object, _ := item.(yaml.MapSlice)
+			merged := defined[index]
+			for _, detail := range without(object, append(customTypeSDLKeys, "name")...) {
+				merged = replace(merged, fmt.Sprint(detail.Key), detail.Value)
+			}
+			response[index] = merged
+		}
+
+		if len(response) > 0 {
+			empty = false
+		}
+		customTypes = append(customTypes, yaml.MapItem{Key: kind.key, Value: response})
+	}
+
+	if empty {
+		return actions, nil, nil
+	}
+
+	return actions, customTypes, nil
+}
+
+//  Splits actions and custom types into contents of actions.yaml and actions.graphql
+func splitActions(actions, customTypes interface{}) (yaml.MapSlice, string, error) {
+
+	var sdl []string
+
+	definitions := []interface{}{}
+	fields := make(map[string][]string)
+
+	list, _ := actions.([]interface{})
+	for _, item := range list {
+		action, ok := item.(yaml.MapSlice)
+		if !ok {
+			return nil, "", errors.New("invalid action in metadata")
+		}
+
+		name, _ := lookup(action, "name")
+		definition, _ := lookup(action, "definition")
+		object, _ := definition.(yaml.MapSlice)
+
+		//  Actions are mutations, unless mentioned otherwise
+		kind := "mutation"
+		if value, ok := lookup(object, "type"); ok && value != nil {
+			kind = fmt.Sprint(value)
+		}
+
+		arguments, _ := lookup(object, "arguments")
+		output, _ := lookup(object, "output_type")
+		fields[kind] = append(fields[kind], printField(fmt.Sprint(name), arguments, fmt.Sprint(output)))
+
+		definitions = append(definitions, replace(action, "definition", without(object, actionSignatureKeys...)))
+	}
+
+	for _, root := range []struct{ kind, name string }{{"query", "Query"}, {"mutation", "Mutation"}} {
+		if len(fields[root.kind]) > 0 {
+			sdl = append(sdl, fmt.Sprintf("type %s {\n%s}\n", root.name, strings.Join(fields[root.kind], "")))
+		}
+	}
+
+	types := yaml.MapSlice{}
+	for _, kind := range []string{"enums", "input_objects", "objects", "scalars"} {
+
+		keyword := ""
+		for _, item := range customTypeKinds {
+			if item.key == kind {
+				keyword = item.keyword
+			}
+		}
+
+		response := []interface{}{}
+		list, _ := lookup(customTypes, kind)
+		items, _ := list.([]interface{})
+
+		for _, item := range items {
+			object, ok := item.(yaml.MapSlice)
+			if !ok {
+				return nil, "", errors.New("invalid custom type in metadata")
+			}
+
+			sdl = append(sdl, printCustomType(keyword, object))
+			response = append(response, without(object, customTypeSDLKeys...))
+		}
+
+		types = append(types, yaml.MapItem{Key: kind, Value: response})
+	}
+
+	return yaml.MapSlice{
+		{Key: "actions", Value: definitions},
+		{Key: "custom_types", Value: types},
+	}, strings.Join(sdl, "\n"), nil
+}
+
+//  Prints the action as a field of Query or Mutation
+func printField(name string, arguments interface{}, output string) string {
+
+	list, _ := arguments.([]interface{})
+	if len(list) == 0 {
+		return fmt.Sprintf("  %s: %s\n", name, output)
+	}
+
+	var response strings.Builder
+	fmt.Fprintf(&response, "  %s (\n", name)
+	for _, item := range list {
+		response.WriteString(printDescription(item, "    "))
+		argument, _ := lookup(item, "name")
+		kind, _ := lookup(item, "type")
+		fmt.Fprintf(&response, "    %v: %v\n", argument, kind)
+	}
+	fmt.Fprintf(&response, "  ): %s\n", output)
+
+	return response.String()
+}
+
+//  Prints the custom type as a GraphQL definition
+func printCustomType(keyword string, object yaml.MapSlice) string {
+
+	var response strings.Builder
+
+	name, _ := lookup(object, "name")
+	response.WriteString(printDescription(object, ""))
+
+	if keyword == "scalar" {
+		fmt.Fprintf(&response, "scalar %v\n", name)
+		return response.String()
+	}
+
+	fmt.Fprintf(&response, "%s %v {\n", keyword, name)
+
+	if keyword == "enum" {
+		values, _ := lookup(object, "values")
+		list, _ := values.([]interface{})
+		for _, item := range list {
+			response.WriteString(printDescription(item, "  "))
+			value, _ := lookup(item, "value")
+			deprecated, _ := lookup(item, "is_deprecated")
+			if deprecated == true {
+				fmt.Fprintf(&response, "  %v @deprecated\n", value)
+			} else {
+				fmt.Fprintf(&response, "  %v\n", value)
+			}
+		}
+	} else {
+		fields, _ := lookup(object, "fields")
+		list, _ := fields.([]interface{})
+		for _, item := range list {
+			response.WriteString(printDescription(item, "  "))
+			field, _ := lookup(item, "name")
+			kind, _ := lookup(item, "type")
+			fmt.Fprintf(&response, "  %v: %v\n", field, kind)
+		}
+	}
+
+	response.WriteString("}\n")
+	return response.String()
+}
+
+//  Prints the description of the object, if it has one, as a block string
+func printDescription(object interface{}, indent string) string {
+
+	description, _ := lookup(object, "description")
+	if description == nil || fmt.Sprint(description) == "" {
+		return ""
+	}
+
+	text := strings.ReplaceAll(fmt.Sprint(description), `"""`, `\"""`)
+
+	//  Quotes at the end would be mistaken for the end of the string
+	if !strings.Contains(text, "\n") && !strings.HasSuffix(text, `"`) {
+		return fmt.Sprintf("%s\"\"\"%s\"\"\"\n", indent, text)
+	}
+
+	return fmt.Sprintf("%s\"\"\"\n%s%s\n%s\"\"\"\n", indent, indent, strings.ReplaceAll(text, "\n", "\n"+indent), indent)
+}
+
+//  Parses definitions of actions and custom types from GraphQL.
+//  Only the subset of GraphQL used by actions is supported.
+func parseActionsSchema(sdl string) (*actionsSchema, error) {
+
+	response := &actionsSchema{
+		actions: make(map[string]yaml.MapSlice),
+		types:   make(map[string][]yaml.MapSlice),
+	}
+
+	tokens, err := tokenize(sdl)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &sdlParser{tokens: tokens}
+	for !p.done() {
+
+		description := p.description()
+
+		keyword, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		if keyword == "extend" {
+			if keyword, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		object := yaml.MapSlice{{Key: "name", Value: name}}
+		if description != nil {
+			object = append(object, yaml.MapItem{Key: "description", Value: *description})
+		}
+
+		switch keyword {
+		case "scalar":
+			p.directives()
+
+		case "enum":
+			p.directives()
+			values, err := p.enumValues()
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, yaml.MapItem{Key: "values", Value: values})
+
+		case "type", "input":
+
+			//  Actions are fields of Query and Mutation types
+			root := keyword == "type" && (name == "Query" || name == "Mutation")
+
+			for !p.done() && p.peek() != "{" {
+				p.next()
+			}
+
+			fields, err := p.fields(root)
+			if err != nil {
+				return nil, err
+			}
+
+			if root {
+				for _, field := range fields {
+					action, _ := lookup(field, "name")
+					definition := yaml.MapSlice{{Key: "type", Value: strings.ToLower(name)}}
+					if arguments, ok := lookup(field, "arguments"); ok {
+						definition = append(definition, yaml.MapItem{Key: "arguments", Value: arguments})
+					}
+					output, _ := lookup(field, "type")
+					response.actions[fmt.Sprint(action)] = append(definition, yaml.MapItem{Key: "output_type", Value: output})
+				}
+				continue
+			}
+
+			list := []interface{}{}
+			for _, field := range fields {
+				list = append(list, field)
+			}
+			object = append(object, yaml.MapItem{Key: "fields", Value: list})
+
+		default:
+			return nil, fmt.Errorf("unsupported definition %q", keyword)
+		}
+
+		for _, kind := range customTypeKinds {
+			if kind.keyword == keyword {
+				response.types[kind.key] = append(response.types[kind.key], object)
+			}
+		}
+	}
+
+	return response, nil
+}
+
+//  Token of GraphQL. Strings are marked by their kind,
+//  to tell them apart from names and punctuation.
+type sdlToken struct {
+	text   string
+	string bool
+}
+
+//  Splits GraphQL into tokens, skipping whitespace, commas and comments
+func tokenize(sdl string) ([]sdlToken, error) {
+
+	var response []sdlToken
+	runes := []rune(sdl)
+
+	for index := 0; index < len(runes); {
+		char := runes[index]
+
+		switch {
+		case unicode.IsSpace(char) || char == ',' || char == '\uFEFF':
+			index++
+
+		case char == '#':
+			for index < len(runes) && runes[index] != '\n' {
+				index++
+			}
+
+		case char == '"' && index+2 < len(runes) && runes[index+1] == '"' && runes[index+2] == '"':
+			start, end := index+3, index+3
+			for ; end+3 <= len(runes) && string(runes[end:end+3]) != `"""`; end++ {
+				if runes[end] == '\\' && end+4 <= len(runes) && string(runes[end+1:end+4]) == `"""` {
+					end += 3
+				}
+			}
+			if end+3 > len(runes) {
+				return nil, errors.New("unterminated block string")
+			}
+			response = append(response, sdlToken{text: blockString(strings.ReplaceAll(string(runes[start:end]), `\"""`, `"""`)), string: true})
+			index = end + 3
+
+		case char == '"':
+			var value strings.Builder
+			index++
+			for ; index < len(runes) && runes[index] != '"'; index++ {
+				if runes[index] == '\n' {
+					return nil, errors.New("unterminated string")
+				}
+				if runes[index] == '\\' && index+1 < len(runes) {
+					index++
+					switch runes[index] {
+					case 'n':
+						value.WriteRune('\n')
+					case 't':
+						value.WriteRune('\t')
+					case 'r':
+						value.WriteRune('\r')
+					default:
+						value.WriteRune(runes[index])
+					}
+					continue
+				}
+				value.WriteRune(runes[index])
+			}
+			if index >= len(runes) {
+				return nil, errors.New("unterminated string")
+			}
+			index++
+			response = append(response, sdlToken{text: value.String(), string: true})
+
+		case char == '_' || char == '-' || unicode.IsLetter(char) || unicode.IsDigit(char):
+			start := index
+			for index < len(runes) && (runes[index] == '_' || runes[index] == '-' || runes[index] == '.' || unicode.IsLetter(runes[index]) || unicode.IsDigit(runes[index])) {
+				index++
+			}
+			response = append(response, sdlToken{text: string(runes[start:index])})
+
+		case strings.ContainsRune("{}()[]:!=@|&$", char):
+			response = append(response, sdlToken{text: string(char)})
+			index++
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q", char)
+		}
+	}
+
+	return response, nil
+}
+
+//  Returns the value of a block string, without the common indentation
+//  of it's lines, and without leading and trailing blank lines
+func blockString(raw string) string {
+
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+
+	for index := range lines {
+		if index > 0 && indent > 0 {
+			if len(lines[index]) >= indent {
+				lines[index] = lines[index][indent:]
+			} else {
+				lines[index] = ""
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type sdlParser struct {
+	tokens   []sdlToken
+	position int
+}
+
+func (p *sdlParser) done() bool {
+	return p.position >= len(p.tokens)
+}
+
+//  Returns the next token, without consuming it.
+//  Strings are never returned, so that they don't match punctuation.
+func (p *sdlParser) peek() string {
+	if p.done() || p.tokens[p.position].string {
+		return ""
+	}
+	return p.tokens[p.position].text
+}
+
+func (p *sdlParser) next() sdlToken {
+	token := p.tokens[p.position]
+	p.position++
+	return token
+}
+
+func (p *sdlParser) expect(text string) error {
+	if p.peek() != text {
+		return p.unexpected(text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *sdlParser) unexpected(want string) error {
+	if p.done() {
+		return fmt.Errorf("expected %s, found end of file", want)
+	}
+	return fmt.Errorf("expected %s, found %q", want, p.tokens[p.position].text)
+}
+
+//  Consumes a description, if there is one
+func (p *sdlParser) description() *string {
+	if !p.done() && p.tokens[p.position].string {
+		text := p.next().text
+		return &text
+	}
+	return nil
+}
+
+func (p *sdlParser) name() (string, error) {
+	text := p.peek()
+	if text == "" || strings.ContainsAny(text[:1], "{}()[]:!=@|&$") {
+		return "", p.unexpected("a name")
+	}
+	p.next()
+	return text, nil
+}
+
+//  Parses a type reference. Example: [String!]!
+func (p *sdlParser) typeReference() (string, error) {
+
+	var response string
+
+	if p.peek() == "[" {
+		p.next()
+		item, err := p.typeReference()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		response = "[" + item + "]"
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		response = name
+	}
+
+	if p.peek() == "!" {
+		p.next()
+		response += "!"
+	}
+
+	return response, nil
+}
+
+//  Consumes directives, and returns their names
+func (p *sdlParser) directives() []string {
+
+	var response []string
+	for p.peek() == "@" {
+		p.next()
+		if name, err := p.name(); err == nil {
+			response = append(response, name)
+		}
+		if p.peek() == "(" {
+			p.skipBlock("(", ")")
+		}
+	}
+
+	return response
+}
+
+//  Consumes a value, like a default one
+func (p *sdlParser) value() {
+	switch p.peek() {
+	case "[":
+		p.skipBlock("[", "]")
+	case "{":
+		p.skipBlock("{", "}")
+	default:
+		if !p.done() {
+			p.next()
+		}
+	}
+}
+
+//  Consumes everything up to the matching closing token
+func (p *sdlParser) skipBlock(open, close string) {
+	depth := 0
+	for !p.done() {
+		switch p.peek() {
+		case open:
+			depth++
+		case close:
+			depth--
+		}
+		p.next()
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+//  Parses fields of a type or input. Arguments are only kept for actions.
+func (p *sdlParser) fields(arguments bool) ([]yaml.MapSlice, error) {
+
+	var response []yaml.MapSlice
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for p.peek() != "}" {
+		if p.done() {
+			return nil, p.unexpected("}")
+		}
+
+		field, err := p.inputValue()
+		if err != nil {
+			return nil, err
+		}
+
+		//  Inputs are parsed the same way, only without arguments
+		if p.peek() == "(" {
+			p.next()
+
+			list := []interface{}{}
+			for p.peek() != ")" {
+				if p.done() {
+					return nil, p.unexpected(")")
+				}
+				argument, err := p.inputValue()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.typed(&argument); err != nil {
+					return nil, err
+				}
+				list = append(list, argument)
+			}
+			p.next()
+
+			if arguments && len(list) > 0 {
+				field = append(field, yaml.MapItem{Key: "arguments", Value: list})
+			}
+		}
+
+		if err := p.typed(&field); err != nil {
+			return nil, err
+		}
+
+		response = append(response, field)
+	}
+	p.next()
+
+	return response, nil
+}
+
+//  Parses the description and name of a field or argument
+func (p *sdlParser) inputValue() (yaml.MapSlice, error) {
+
+	description := p.description()
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	response := yaml.MapSlice{{Key: "name", Value: name}}
+	if description != nil {
+		response = append(response, yaml.MapItem{Key: "description", Value: *description})
+	}
+
+	return response, nil
+}
+
+//  Parses the type of a field or argument, along with
+//  it's default value and directives, which are ignored
+func (p *sdlParser) typed(object *yaml.MapSlice) error {
+
+	if err := p.expect(":"); err != nil {
+		return err
+	}
+
+	kind, err := p.typeReference()
+	if err != nil {
+		return err
+	}
+
+	if p.peek() == "=" {
+		p.next()
+		p.value()
+	}
+	p.directives()
+
+	*object = append(*object, yaml.MapItem{Key: "type", Value: kind})
+	return nil
+}
+
+//  Parses values of an enum
+func (p *sdlParser) enumValues() ([]interface{}, error) {
+
+	response := []interface{}{}
+
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for p.peek() != "}" {
+		if p.done() {
+			return nil, p.unexpected("}")
+		}
+
+		description := p.description()
+
+		value, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		object := yaml.MapSlice{{Key: "value", Value: value}}
+		if description != nil {
+			object = append(object, yaml.MapItem{Key: "description", Value: *description})
+		}
+
+		for _, directive := range p.directives() {
+			if directive == "deprecated" {
+				object = append(object, yaml.MapItem{Key: "is_deprecated", Value: true})
+			}
+		}
+
+		response = append(response, object)
+	}
+	p.next()
+
+	return response, nil
+}
@@ -0,0 +1,346 @@
+package hasura
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/nhost/cli/nhost"
+	"github.com/sirupsen/logrus"
+)
+
+//  Environment variable pointing to a local Hasura CLI,
+//  which is used instead of downloading one
+const CLI_ENV = "NHOST_HASURA_CLI"
+
+var (
+
+	//  Location of Hasura CLI releases, and the API describing them.
+	//  Both contain the release version.
+	CLI_DOWNLOAD_URL = "https://github.com/hasura/graphql-engine/releases/download/%s/%s"
+	CLI_RELEASE_URL  = "https://api.github.com/repos/hasura/graphql-engine/releases/tags/%s"
+
+	//  Release version in graphql-engine image tags. Example: v2.2.0.cli-migrations-v3
+	versionPattern = regexp.MustCompile(`^v?(\d+\.\d+\.\d+(-(alpha|beta)\.\d+)?)`)
+)
+
+//  Returns the path of the Hasura CLI, matching the version of graphql-engine in config.yaml.
+//
+//  Every version is downloaded once to $HOME/.nhost/bin/hasura-<version>,
+//  and reused afterwards, even offline.
+//  Set NHOST_HASURA_CLI to use a local binary instead.
+func Binary() (string, error) {
+
+	if path := os.Getenv(CLI_ENV); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("%s points to %s, which can't be used: %w", CLI_ENV, path, err)
+		}
+		return path, nil
+	}
+
+	version, err := cliVersion(nhost.HasuraVersion())
+	if err != nil {
+		return "", err
+	}
+
+	path := cliPath(version)
+
+	log.WithFields(logrus.Fields{
+		"type":    "hasura",
+		"version": version,
+	}).Debug("Fetching binary")
+
+	//  Reuse a previous download, unless it has been corrupted since then,
+	//  or it doesn't match a checksum published after it was downloaded
+	if _, err := os.Stat(path); err == nil {
+		verified, err := verifyChecksum(path)
+		if err == nil && !verified {
+			verified, err = recheckChecksum(version, path)
+		}
+
+		if err != nil {
+			log.WithField("component", path).Debug(err)
+		} else {
+			if !verified {
+				status.Warnln(fmt.Sprintf("Using Hasura CLI %s, which couldn't be verified against a published checksum", version))
+			}
+			return path, nil
+		}
+	}
+
+	if err := downloadCLI(version, path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+//  Returns the release of the Hasura CLI matching the graphql-engine version.
+//  Example: v2.2.0.cli-migrations-v3 -> v2.2.0
+func cliVersion(engine string) (string, error) {
+
+	match := versionPattern.FindStringSubmatch(engine)
+	if match == nil {
+		return "", fmt.Errorf("unsupported hasura version %q, set %s to use a local Hasura CLI", engine, CLI_ENV)
+	}
+
+	return "v" + match[1], nil
+}
+
+//  Returns the location of the Hasura CLI of the version
+func cliPath(version string) string {
+
+	name := "hasura-" + version
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	return filepath.Join(nhost.ROOT, "bin", name)
+}
+
+//  Returns the name of the release asset of the Hasura CLI, for the architecture
+func cliAsset(architecture string) string {
+
+	name := fmt.Sprintf("cli-hasura-%s-%s", runtime.GOOS, architecture)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	return name
+}
+
+//  Downloads the Hasura CLI of the version to the path.
+//  The binary is written to a temporary file first, and only moved
+//  to the path once it's complete, and matches its published checksum.
+//
+//  If the release can't be fetched, nothing is downloaded. If the release has no checksum
+//  of the binary, like releases older than GitHub's digests, the binary is used
+//  with a warning, and it's checksum is recorded as unverified.
+func downloadCLI(version, path string) error {
+
+	status.Executing(fmt.Sprintf("Downloading Hasura CLI %s for %s-%s", version, runtime.GOOS, runtime.GOARCH))
+
+	published, err := publishedChecksums(version)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the checksum of Hasura CLI %s: %w", version, err)
+	}
+
+	resp, asset, err := fetchCLI(version)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(temp, hash), resp.Body)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download Hasura CLI %s: %w", version, err)
+	}
+
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return fmt.Errorf("failed to download Hasura CLI %s: received %d of %d bytes", version, written, resp.ContentLength)
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+
+	expected, verified := published[asset]
+	if !verified {
+		log.WithField("component", asset).Debug("Release has no checksum of the asset")
+		status.Warnln(fmt.Sprintf("Couldn't verify Hasura CLI %s, since it's release has no checksum of it", version))
+	} else if expected != checksum {
+		return fmt.Errorf("checksum of Hasura CLI %s doesn't match the published one, expected %s, got %s", version, expected, checksum)
+	}
+
+	if err := os.Chmod(temp.Name(), 0755); err != nil {
+		return err
+	}
+
+	//  Checksums of previous downloads don't apply anymore
+	for _, trusted := range []bool{true, false} {
+		if err := os.Remove(checksumPath(path, trusted)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return err
+	}
+
+	//  Without a recorded checksum, the binary is downloaded again next time
+	return ioutil.WriteFile(checksumPath(path, verified), []byte(checksum+"\n"), 0644)
+}
+
+//  Returns the location of the checksum recorded for the binary.
+//  Checksums which haven't been verified against published ones are kept apart.
+func checksumPath(path string, verified bool) string {
+
+	if verified {
+		return path + ".sha256"
+	}
+
+	return path + ".sha256.unverified"
+}
+
+//  Returns the architectures of the Hasura CLI which run on this machine, preferred first
+func cliArchitectures() []string {
+
+	response := []string{runtime.GOARCH}
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		response = append(response, "amd64")
+	}
+
+	return response
+}
+
+//  Requests the release asset of the Hasura CLI of the version.
+//  Falls back to the Intel binary on Apple Silicon, for releases
+//  without a native one, since it runs there through Rosetta.
+func fetchCLI(version string) (*http.Response, string, error) {
+
+	for _, architecture := range cliArchitectures() {
+
+		asset := cliAsset(architecture)
+
+		resp, err := http.Get(fmt.Sprintf(CLI_DOWNLOAD_URL, version, asset))
+		if err != nil {
+			return nil, "", err
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			return resp, asset, nil
+		}
+
+		resp.Body.Close()
+		log.WithField("component", asset).Debug("Failed to download: ", resp.Status)
+	}
+
+	return nil, "", fmt.Errorf("no Hasura CLI %s is available for %s-%s, set %s to use a local one", version, runtime.GOOS, runtime.GOARCH, CLI_ENV)
+}
+
+//  Fetches the SHA-256 checksums of release assets, published by GitHub, by their names.
+//  Assets without a checksum are left out.
+func publishedChecksums(version string) (map[string]string, error) {
+
+	resp, err := http.Get(fmt.Sprintf(CLI_RELEASE_URL, version))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch release %s: %s", version, resp.Status)
+	}
+
+	var release struct {
+		Assets []struct {
+			Name   string `json:"name"`
+			Digest string `json:"digest"`
+		} `json:"assets"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, err
+	}
+
+	response := make(map[string]string)
+	for _, item := range release.Assets {
+		if strings.HasPrefix(item.Digest, "sha256:") {
+			response[item.Name] = strings.TrimPrefix(item.Digest, "sha256:")
+		}
+	}
+
+	return response, nil
+}
+
+//  Verifies a binary, which was downloaded without a published checksum,
+//  against the checksums published since then. Once one matches, it's recorded as verified.
+//  Returns an error if the binary doesn't match them, so that it's downloaded again.
+//  While the release can't be fetched, like offline, the binary stays unverified.
+func recheckChecksum(version, path string) (bool, error) {
+
+	recorded, err := ioutil.ReadFile(checksumPath(path, false))
+	if err != nil {
+		return false, err
+	}
+
+	published, err := publishedChecksums(version)
+	if err != nil {
+		log.WithField("component", path).Debug("Failed to fetch published checksums: ", err)
+		return false, nil
+	}
+
+	var found bool
+	for _, architecture := range cliArchitectures() {
+
+		expected, ok := published[cliAsset(architecture)]
+		if !ok {
+			continue
+		}
+
+		if expected == strings.TrimSpace(string(recorded)) {
+			return true, os.Rename(checksumPath(path, false), checksumPath(path, true))
+		}
+		found = true
+	}
+
+	if found {
+		return false, fmt.Errorf("checksum of Hasura CLI %s doesn't match the published one", version)
+	}
+
+	return false, nil
+}
+
+//  Verifies the binary against the checksum recorded when it was downloaded.
+//  Returns whether that checksum had been verified against the published one.
+func verifyChecksum(path string) (bool, error) {
+
+	verified := true
+
+	recorded, err := ioutil.ReadFile(checksumPath(path, true))
+	if os.IsNotExist(err) {
+		verified = false
+		recorded, err = ioutil.ReadFile(checksumPath(path, false))
+	}
+	if err != nil {
+		return false, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return false, err
+	}
+
+	if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != strings.TrimSpace(string(recorded)) {
+		return false, fmt.Errorf("checksum %s doesn't match the recorded one", checksum)
+	}
+
+	return verified, nil
+}
@@ -0,0 +1,175 @@
+package hasura
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/nhost/cli/nhost"
+	"github.com/nhost/cli/util"
+)
+
+func TestBinary(t *testing.T) {
+
+	binary := []byte("#!/bin/sh\necho hasura\n")
+	sum := sha256.Sum256(binary)
+	digest := hex.EncodeToString(sum[:])
+
+	var downloads int
+	unpublished := "null"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/download/v2.2.0/"):
+			downloads++
+			w.Write(binary)
+		case r.URL.Path == "/releases/v2.2.0":
+			fmt.Fprintf(w, `{"assets": [{"name": %q, "digest": "sha256:%s"}]}`, cliAsset(runtime.GOARCH), digest)
+		case r.URL.Path == "/releases/v2.3.0":
+			fmt.Fprintf(w, `{"assets": [{"name": %q, "digest": "sha256:%064d"}]}`, cliAsset(runtime.GOARCH), 0)
+		case strings.HasPrefix(r.URL.Path, "/download/v2.3.0/"), strings.HasPrefix(r.URL.Path, "/download/v2.4.0/"):
+			w.Write(binary)
+		case r.URL.Path == "/releases/v2.4.0":
+			fmt.Fprintf(w, `{"assets": [{"name": %q, "digest": %s}]}`, cliAsset(runtime.GOARCH), unpublished)
+		case strings.HasPrefix(r.URL.Path, "/download/v2.5.0/"):
+			downloads++
+			w.Write(binary)
+		case r.URL.Path == "/releases/v2.5.0":
+			http.Error(w, "rate limit exceeded", http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	defer func(download, release string) {
+		CLI_DOWNLOAD_URL, CLI_RELEASE_URL = download, release
+	}(CLI_DOWNLOAD_URL, CLI_RELEASE_URL)
+	CLI_DOWNLOAD_URL = server.URL + "/download/%s/%s"
+	CLI_RELEASE_URL = server.URL + "/releases/%s"
+
+	dir := t.TempDir()
+	nhost.ROOT = filepath.Join(dir, ".nhost")
+	nhost.CONFIG_PATH = filepath.Join(dir, "config.yaml")
+	os.Unsetenv(CLI_ENV)
+
+	setVersion := func(version string) {
+		config := fmt.Sprintf("services:\n  hasura:\n    version: %s\n", version)
+		if err := ioutil.WriteFile(nhost.CONFIG_PATH, []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//  The version of graphql-engine decides the version of the CLI
+	setVersion("v2.2.0.cli-migrations-v3")
+
+	path, err := Binary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != cliPath("v2.2.0") {
+		t.Errorf("Binary() = %s, want %s", path, cliPath("v2.2.0"))
+	}
+
+	if data, _ := ioutil.ReadFile(path); string(data) != string(binary) {
+		t.Errorf("Binary() wrote %q, want %q", data, binary)
+	}
+
+	//  Downloads are reused, even offline
+	if _, err := Binary(); err != nil || downloads != 1 {
+		t.Errorf("Binary() downloaded %d times, want 1, error: %v", downloads, err)
+	}
+
+	//  Corrupted downloads are replaced
+	if err := ioutil.WriteFile(path, []byte("truncated"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Binary(); err != nil || downloads != 2 {
+		t.Errorf("Binary() downloaded %d times, want 2, error: %v", downloads, err)
+	}
+
+	//  Downloads not matching the published checksum are discarded
+	setVersion("v2.3.0")
+
+	if _, err := Binary(); err == nil {
+		t.Error("Binary() succeeded with a wrong checksum, want an error")
+	}
+
+	if files, _ := ioutil.ReadDir(filepath.Join(nhost.ROOT, "bin")); len(files) != 2 {
+		t.Errorf("Binary() left %d files behind, want only v2.2.0 and its checksum", len(files))
+	}
+
+	//  Unknown versions can't be downloaded
+	setVersion("v9.9.9")
+
+	if _, err := Binary(); err == nil {
+		t.Error("Binary() succeeded with an unknown version, want an error")
+	}
+
+	//  Downloads without a published checksum are used, but never trusted
+	setVersion("v2.4.0")
+
+	if _, err := Binary(); err != nil {
+		t.Fatal(err)
+	}
+
+	if util.PathExists(checksumPath(cliPath("v2.4.0"), true)) || !util.PathExists(checksumPath(cliPath("v2.4.0"), false)) {
+		t.Error("Binary() recorded the checksum of an unverified download as verified")
+	}
+
+	if verified, err := verifyChecksum(cliPath("v2.4.0")); err != nil || verified {
+		t.Errorf("verifyChecksum() = %v, %v, want an unverified checksum", verified, err)
+	}
+
+	//  Unverified downloads are verified, once their checksum is published
+	unpublished = fmt.Sprintf("%q", "sha256:"+digest)
+
+	if _, err := Binary(); err != nil {
+		t.Fatal(err)
+	}
+
+	if verified, err := verifyChecksum(cliPath("v2.4.0")); err != nil || !verified {
+		t.Errorf("verifyChecksum() = %v, %v, want a verified checksum", verified, err)
+	}
+
+	//  Unverified downloads not matching the published checksum are downloaded again
+	if err := os.Rename(checksumPath(cliPath("v2.4.0"), true), checksumPath(cliPath("v2.4.0"), false)); err != nil {
+		t.Fatal(err)
+	}
+	unpublished = fmt.Sprintf(`"sha256:%064d"`, 0)
+
+	if _, err := Binary(); err == nil {
+		t.Error("Binary() reused a download not matching the published checksum, want an error")
+	}
+
+	//  Nothing is downloaded without the release, like when GitHub's API is rate limited
+	downloads = 0
+	setVersion("v2.5.0")
+
+	if _, err := Binary(); err == nil || downloads != 0 || util.PathExists(cliPath("v2.5.0")) {
+		t.Errorf("Binary() downloaded %d times without the release, error: %v", downloads, err)
+	}
+
+	//  A local binary can be used instead
+	os.Setenv(CLI_ENV, path)
+	defer os.Unsetenv(CLI_ENV)
+
+	if got, err := Binary(); err != nil || got != path {
+		t.Errorf("Binary() = %s, %v, want %s", got, err, path)
+	}
+
+	os.Setenv(CLI_ENV, filepath.Join(dir, "missing"))
+
+	if _, err := Binary(); err == nil {
+		t.Errorf("Binary() succeeded with a missing %s, want an error", CLI_ENV)
+	}
+}
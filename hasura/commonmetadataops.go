@@ -1,6 +1,7 @@
 package hasura
 
 import (
+	"encoding/json"
 	"io"
 )
 
@@ -22,23 +23,22 @@ type V2ReplaceMetadataArgs struct {
 }
 
 type V2ReplaceMetadataResponse struct {
-	IsConsistent        bool        `json:"is_consistent"`
-	InconsistentObjects interface{} `json:"inconsistent_objects"`
+	IsConsistent        bool                 `json:"is_consistent"`
+	InconsistentObjects []InconsistentObject `json:"inconsistent_objects"`
 }
 
 type InconsistentMetadataResponse struct {
-	IsConsistent        bool `json:"is_consistent"`
-	InconsistentObjects []struct {
-		Type       string `json:"type"`
-		Name       string `json:"name"`
-		Reason     string `json:"reason"`
-		Definition struct {
-			Name   string `json:"name"`
-			Schema string `json:"schema"`
-		} `json:"definition"`
-		Table struct {
-			Name   string `json:"name"`
-			Schema string `json:"schema"`
-		} `json:"table"`
-	} `json:"inconsistent_objects"`
+	IsConsistent        bool                 `json:"is_consistent"`
+	InconsistentObjects []InconsistentObject `json:"inconsistent_objects"`
+}
+
+//  Metadata object which Hasura failed to apply
+type InconsistentObject struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+
+	//  Definition of the object in metadata,
+	//  it's shape depends on the type of object
+	Definition json.RawMessage `json:"definition"`
 }
@@ -4,13 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -25,99 +22,6 @@ import (
 var status = &util.Writer
 var log = &logger.Log
 
-func getBinary() string {
-	switch runtime.GOOS {
-	case "windows":
-		return filepath.Join(nhost.ROOT, "hasura.exe")
-	default:
-		return filepath.Join(nhost.ROOT, "hasura")
-	}
-}
-
-//  if the required binary exists in $HOME/.nhost
-//  this function returns it's exact path
-//  and if the binary doesn't exist,
-//  it downloads it from specifically supplied URL
-//  based on user's OS and ARCH
-func Binary() (string, error) {
-
-	binaryPath := getBinary()
-
-	//  search for installed binary
-	if pathExists(binaryPath) {
-		return binaryPath, nil
-	}
-
-	var url string
-
-	binary := "hasura"
-
-	version := "v2.0.9"
-
-	log.WithFields(logrus.Fields{
-		"type":    binary,
-		"version": version,
-	}).Debug("Fetching binary")
-
-	//	Use AMD architecture instead of ARM
-	architecture := runtime.GOARCH
-	if strings.Contains(architecture, "arm") {
-		architecture = strings.ReplaceAll(architecture, "arm", "amd")
-	}
-
-	url = fmt.Sprintf("https://github.com/hasura/graphql-engine/releases/download/%v/cli-hasura-%v-%v", version, runtime.GOOS, architecture)
-
-	//  create the binary path
-	if err := os.MkdirAll(nhost.ROOT, os.ModePerm); err != nil {
-		return "", err
-	}
-
-	out, err := os.Create(binaryPath)
-	if err != nil {
-		return "", err
-	}
-
-	defer out.Close()
-
-	//  update binary download URL depending upon the OS
-	if runtime.GOOS == "windows" {
-		url += ".exe"
-	}
-
-	status.Executing(fmt.Sprintf("Downloading %s binary for %s-%s", binary, runtime.GOOS, runtime.GOARCH))
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	log.WithField("component", fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH)).Debugf("Writing %s binary", binary)
-
-	_, err = io.Copy(out, resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	//  Change permissions so that the download file
-	//  can become accessible and executable
-	err = os.Chmod(binaryPath, 0777)
-
-	if err != nil {
-		return "", err
-	}
-
-	//return the path at which binary has been
-	//  downloaded and saved
-	return binaryPath, nil
-}
-
-//  validates whether a given folder/file path exists or not
-func pathExists(filePath string) bool {
-	_, err := os.Stat(filePath)
-	return err == nil
-}
-
 func (c *Client) GetSchemas() ([]string, error) {
 
 	log.Debug("Fetching schema list")
@@ -208,36 +112,6 @@ func (c *Client) GetMetadata() (*MetadataV3, error) {
 	return &responseData.Metadata, nil
 }
 
-func (c *Client) GetInconsistentMetadata() (InconsistentMetadataResponse, error) {
-
-	log.Debug("Fetching inconsistent metadata")
-
-	var response InconsistentMetadataResponse
-
-	reqBody := RequestBody{
-		Type: "get_inconsistent_metadata",
-	}
-
-	body, err := reqBody.Marshal()
-	if err != nil {
-		return response, err
-	}
-
-	resp, err := c.Request(body, "/v1/metadata")
-	if err != nil {
-		return response, err
-	}
-	defer resp.Body.Close()
-
-	body, err = ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return response, err
-	}
-
-	err = json.Unmarshal(body, &response)
-	return response, err
-}
-
 func (c *Client) Seed(payload string) error {
 
 	reqBody := RequestBody{
@@ -269,61 +143,6 @@ func (c *Client) Seed(payload string) error {
 	return nil
 }
 
-func (c *Client) ClearMigration(source string) error {
-
-	log.Debug("Clearing migration")
-
-	args := []string{c.CLI,
-		"migrate",
-		"delete",
-		"--all",
-		"--server",
-		"--endpoint",
-		c.Endpoint,
-		"--admin-secret",
-		c.AdminSecret,
-		"--database-name",
-		source,
-		"--skip-update-check",
-		"--force",
-	}
-
-	execute := exec.Cmd{
-		Path: c.CLI,
-		Args: args,
-		Dir:  nhost.NHOST_DIR,
-	}
-	output, err := execute.CombinedOutput()
-	if err != nil {
-		log.Debug(string(output))
-		return err
-	}
-
-	/* 	reqBody := RequestBody{
-	   		Type: "run_sql",
-	   		Args: map[string]string{
-	   			"source": source,
-	   			"sql":    "TRUNCATE hdb_catalog.schema_migrations;",
-	   		},
-	   	}
-
-	   	body, err := reqBody.Marshal()
-	   	if err != nil {
-	   		return err
-	   	}
-
-	   	resp, err := c.Request(body, "/v2/query")
-	   	if err != nil {
-	   		return err
-	   	}
-
-	   	if resp.StatusCode != http.StatusOK {
-	   		return errors.New("failed to clear migration")
-	   	}
-	*/
-	return nil
-}
-
 func (c *Client) GetExtensions() ([]string, error) {
 
 	log.Debug("Fetching extensions")
@@ -421,112 +240,6 @@ func (c *Client) Track(table TableEntry) error {
 	return errors.New(response.Error)
 }
 
-/*
-func (c *ClientCommonMetadataOps) ClearMetadata() (io.Reader, error) {
-	request := hasura.RequestBody{
-		Type: "clear_metadata",
-		Args: map[string]string{},
-	}
-	responseBody := new(bytes.Buffer)
-	response, err := c.send(request, responseBody)
-	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s", responseBody.String())
-	}
-	return responseBody, nil
-}
-
-func (c *ClientCommonMetadataOps) ReloadMetadata() (metadata io.Reader, err error) {
-	request := hasura.RequestBody{
-		Type: "reload_metadata",
-		Args: map[string]string{},
-	}
-	responseBody := new(bytes.Buffer)
-	response, err := c.send(request, responseBody)
-	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s", responseBody.String())
-	}
-	return responseBody, nil
-}
-
-func (c *ClientCommonMetadataOps) DropInconsistentMetadata() (metadata io.Reader, err error) {
-	request := hasura.RequestBody{
-		Type: "drop_inconsistent_metadata",
-		Args: map[string]string{},
-	}
-	responseBody := new(bytes.Buffer)
-	response, err := c.send(request, responseBody)
-	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s", responseBody.String())
-	}
-	return responseBody, nil
-}
-
-func (c *ClientCommonMetadataOps) ReplaceMetadata(metadata io.Reader) (io.Reader, error) {
-	var body interface{}
-	if err := json.NewDecoder(metadata).Decode(&body); err != nil {
-		return nil, fmt.Errorf("decoding json: %w", err)
-	}
-	request := hasura.RequestBody{
-		Type: "replace_metadata",
-		Args: body,
-	}
-	responseBody := new(bytes.Buffer)
-	response, err := c.send(request, responseBody)
-	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s", responseBody.String())
-	}
-	return responseBody, nil
-}
-
-func (c *ClientCommonMetadataOps) GetInconsistentMetadata() (*hasura.GetInconsistentMetadataResponse, error) {
-	request := hasura.RequestBody{
-		Type: "get_inconsistent_metadata",
-		Args: map[string]string{},
-	}
-	responseBody := new(bytes.Buffer)
-	response, err := c.send(request, responseBody)
-	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s", responseBody.String())
-	}
-	inconsistentMetadata := new(hasura.GetInconsistentMetadataResponse)
-	if err := json.NewDecoder(responseBody).Decode(inconsistentMetadata); err != nil {
-		return nil, fmt.Errorf("decoding response: %w", err)
-	}
-	return inconsistentMetadata, nil
-}
-
-func (c *ClientCommonMetadataOps) GetInconsistentMetadataReader() (io.Reader, error) {
-	request := hasura.RequestBody{
-		Type: "get_inconsistent_metadata",
-		Args: map[string]string{},
-	}
-	responseBody := new(bytes.Buffer)
-	response, err := c.send(request, responseBody)
-	if err != nil {
-		return nil, err
-	}
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s", responseBody.String())
-	}
-	return responseBody, nil
-}
-*/
-
 func (c *Client) Migration(options []string) ([]byte, error) {
 
 	log.Debug("Performing migration")
@@ -0,0 +1,625 @@
+package hasura
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nhost/cli/util"
+	"gopkg.in/yaml.v2"
+)
+
+//  Version of the metadata directory format, written by the Hasura CLI
+const METADATA_VERSION = 3
+
+//  Prefix of strings, which are replaced by contents of the file they mention
+const INCLUDE_PREFIX = "!include "
+
+//  Files of the metadata directory, other than databases and actions,
+//  by the keys of their contents in exported metadata
+var metadataFiles = map[string]string{
+	"allowlist":                    "allow_list.yaml",
+	"api_limits":                   "api_limits.yaml",
+	"cron_triggers":                "cron_triggers.yaml",
+	"graphql_schema_introspection": "graphql_schema_introspection.yaml",
+	"inherited_roles":              "inherited_roles.yaml",
+	"network":                      "network.yaml",
+	"query_collections":            "query_collections.yaml",
+	"remote_schemas":               "remote_schemas.yaml",
+	"rest_endpoints":               "rest_endpoints.yaml",
+}
+
+//  Files containing a single object, instead of a list
+var metadataObjects = []string{"api_limits", "graphql_schema_introspection", "network"}
+
+//  Hasura metadata. Objects in it are yaml.MapSlice,
+//  to keep the order of their keys, the way Hasura exports them.
+type Metadata yaml.MapSlice
+
+//  Difference between a file of two metadata
+type MetadataDiff struct {
+	Path string
+	Diff string
+}
+
+//  Fetches the metadata of the server
+func (c *Client) FetchMetadata() (Metadata, error) {
+
+	body, err := c.ExportMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseMetadata(body)
+}
+
+//  Parses metadata exported as JSON, keeping the order of keys
+func ParseMetadata(reader io.Reader) (Metadata, error) {
+
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	value, err := decodeJSON(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	response, ok := value.(yaml.MapSlice)
+	if !ok {
+		return nil, errors.New("metadata is not an object")
+	}
+
+	return Metadata(response), nil
+}
+
+//  Loads the metadata from a directory in the format of the Hasura CLI,
+//  resolving all included files. Example: nhost/metadata
+func LoadMetadata(dir string) (Metadata, error) {
+
+	log.WithField("component", util.Rel(dir)).Debug("Loading metadata")
+
+	version, err := readMetadataFile(dir, "version.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	if version == nil {
+		return nil, fmt.Errorf("metadata not found in %s", util.Rel(dir))
+	}
+
+	if value, _ := lookup(version, "version"); fmt.Sprint(value) != fmt.Sprint(METADATA_VERSION) {
+		return nil, fmt.Errorf("unsupported metadata version %v, only version %d is supported", value, METADATA_VERSION)
+	}
+
+	response := Metadata{{Key: "version", Value: METADATA_VERSION}}
+
+	sources, err := readMetadataFile(dir, filepath.Join("databases", "databases.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	if !isEmpty(sources) {
+		response = append(response, yaml.MapItem{Key: "sources", Value: sources})
+	}
+
+	actions, customTypes, err := loadActions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isEmpty(actions) {
+		response = append(response, yaml.MapItem{Key: "actions", Value: actions})
+	}
+
+	if !isEmpty(customTypes) {
+		response = append(response, yaml.MapItem{Key: "custom_types", Value: customTypes})
+	}
+
+	var keys []string
+	for key := range metadataFiles {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, err := readMetadataFile(dir, metadataFiles[key])
+		if err != nil {
+			return nil, err
+		}
+
+		if !isEmpty(value) {
+			response = append(response, yaml.MapItem{Key: key, Value: value})
+		}
+	}
+
+	return response, nil
+}
+
+//  Returns the contents of all files of the metadata directory, by their paths.
+//  Every table and function of databases is saved to a separate file.
+func (m Metadata) Files() (map[string][]byte, error) {
+
+	files := make(map[string][]byte)
+
+	put := func(path string, value interface{}) error {
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(path)] = data
+		return nil
+	}
+
+	if err := put("version.yaml", yaml.MapSlice{{Key: "version", Value: METADATA_VERSION}}); err != nil {
+		return files, err
+	}
+
+	sources := []interface{}{}
+	value, _ := lookup(m, "sources")
+	items, _ := value.([]interface{})
+
+	for _, item := range items {
+		source, ok := item.(yaml.MapSlice)
+		if !ok {
+			return files, errors.New("invalid source in metadata")
+		}
+
+		name, _ := lookup(source, "name")
+		dir := fmt.Sprint(name)
+
+		//  Tables and functions are saved in directories of their sources,
+		//  and replaced by references to them
+		for _, kind := range []struct{ key, object string }{{"tables", "table"}, {"functions", "function"}} {
+
+			value, ok := lookup(source, kind.key)
+			objects, _ := value.([]interface{})
+			if !ok || (kind.key == "functions" && len(objects) == 0) {
+				continue
+			}
+
+			includes := []interface{}{}
+			for _, object := range objects {
+				identifier, _ := lookup(object, kind.object)
+				file := objectFileName(identifier) + ".yaml"
+				if err := put(filepath.Join("databases", dir, kind.key, file), object); err != nil {
+					return files, err
+				}
+				includes = append(includes, INCLUDE_PREFIX+file)
+			}
+
+			if err := put(filepath.Join("databases", dir, kind.key, kind.key+".yaml"), includes); err != nil {
+				return files, err
+			}
+
+			source = replace(source, kind.key, INCLUDE_PREFIX+filepath.ToSlash(filepath.Join(dir, kind.key, kind.key+".yaml")))
+		}
+
+		sources = append(sources, source)
+	}
+
+	if err := put(filepath.Join("databases", "databases.yaml"), sources); err != nil {
+		return files, err
+	}
+
+	actions, _ := lookup(m, "actions")
+	customTypes, _ := lookup(m, "custom_types")
+
+	definitions, sdl, err := splitActions(actions, customTypes)
+	if err != nil {
+		return files, err
+	}
+
+	if err := put("actions.yaml", definitions); err != nil {
+		return files, err
+	}
+	files["actions.graphql"] = []byte(sdl)
+
+	for key, file := range metadataFiles {
+		value, ok := lookup(m, key)
+		if !ok {
+			value = []interface{}{}
+			if util.Contains(metadataObjects, key) {
+				value = yaml.MapSlice{}
+			}
+		}
+
+		if err := put(file, value); err != nil {
+			return files, err
+		}
+	}
+
+	return files, nil
+}
+
+//  Replaces the contents of the metadata directory,
+//  only after all files have been written successfully
+func (m Metadata) Write(dir string) error {
+
+	log.WithField("component", util.Rel(dir)).Debug("Writing metadata")
+
+	for _, item := range m {
+		key := fmt.Sprint(item.Key)
+		if _, ok := metadataFiles[key]; !ok && !util.Contains([]string{"version", "sources", "actions", "custom_types"}, key) {
+			status.Warnln(fmt.Sprintf("Metadata %s is not supported by the metadata directory, and is skipped", key))
+		}
+	}
+
+	files, err := m.Files()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempDir(filepath.Dir(dir), ".metadata-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(temp)
+
+	if err := os.Chmod(temp, 0755); err != nil {
+		return err
+	}
+
+	for path, data := range files {
+		path = filepath.Join(temp, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	return os.Rename(temp, dir)
+}
+
+//  Encodes the metadata as JSON, keeping the order of keys
+func (m Metadata) MarshalJSON() ([]byte, error) {
+
+	var buffer bytes.Buffer
+	if err := encodeJSON(&buffer, yaml.MapSlice(m)); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+//  Compares files of both metadata, ignoring the order of keys.
+//  Returns the differences of changed files, sorted by their paths.
+func DiffMetadata(from, to Metadata, fromName, toName string) ([]MetadataDiff, error) {
+
+	var response []MetadataDiff
+
+	fromFiles, err := Metadata(sortKeys(yaml.MapSlice(from)).(yaml.MapSlice)).Files()
+	if err != nil {
+		return response, err
+	}
+
+	toFiles, err := Metadata(sortKeys(yaml.MapSlice(to)).(yaml.MapSlice)).Files()
+	if err != nil {
+		return response, err
+	}
+
+	var paths []string
+	for path := range fromFiles {
+		paths = append(paths, path)
+	}
+	for path := range toFiles {
+		if _, ok := fromFiles[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+
+		//  Missing files are compared as empty ones
+		diff := util.Diff(
+			filepath.ToSlash(filepath.Join(fromName, path)),
+			filepath.ToSlash(filepath.Join(toName, path)),
+			string(fromFiles[path]),
+			string(toFiles[path]),
+		)
+
+		if diff != "" {
+			response = append(response, MetadataDiff{Path: path, Diff: diff})
+		}
+	}
+
+	return response, nil
+}
+
+//  Reads a YAML file of the metadata directory, resolving included files.
+//  Returns nil, if the file doesn't exist.
+func readMetadataFile(dir, name string) (interface{}, error) {
+
+	path := filepath.Join(dir, name)
+
+	value, err := readYAML(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return value, nil
+}
+
+//  Reads the YAML file, and replaces included files with their contents.
+//  Paths of included files are relative to the directory of the including file.
+func readYAML(path string) (interface{}, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("%s: %w", util.Rel(path), err)
+	}
+
+	return resolveIncludes(ordered(value), filepath.Dir(path))
+}
+
+func resolveIncludes(value interface{}, dir string) (interface{}, error) {
+
+	switch value := value.(type) {
+	case string:
+		if strings.HasPrefix(value, INCLUDE_PREFIX) {
+			return readYAML(filepath.Join(dir, filepath.FromSlash(strings.TrimSpace(strings.TrimPrefix(value, INCLUDE_PREFIX)))))
+		}
+	case yaml.MapSlice:
+		for index, item := range value {
+			resolved, err := resolveIncludes(item.Value, dir)
+			if err != nil {
+				return nil, err
+			}
+			value[index].Value = resolved
+		}
+	case []interface{}:
+		for index, item := range value {
+			resolved, err := resolveIncludes(item, dir)
+			if err != nil {
+				return nil, err
+			}
+			value[index] = resolved
+		}
+	}
+
+	return value, nil
+}
+
+//  Converts maps decoded from YAML into yaml.MapSlice, sorted by keys
+func ordered(value interface{}) interface{} {
+
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		response := yaml.MapSlice{}
+		for key, item := range value {
+			response = append(response, yaml.MapItem{Key: key, Value: ordered(item)})
+		}
+		return sortKeys(response)
+	case []interface{}:
+		response := []interface{}{}
+		for _, item := range value {
+			response = append(response, ordered(item))
+		}
+		return response
+	}
+
+	return value
+}
+
+//  Returns a copy of the value, with keys of all objects sorted
+func sortKeys(value interface{}) interface{} {
+
+	switch value := value.(type) {
+	case yaml.MapSlice:
+		response := yaml.MapSlice{}
+		for _, item := range value {
+			response = append(response, yaml.MapItem{Key: item.Key, Value: sortKeys(item.Value)})
+		}
+		sort.SliceStable(response, func(i, j int) bool {
+			return fmt.Sprint(response[i].Key) < fmt.Sprint(response[j].Key)
+		})
+		return response
+	case []interface{}:
+		response := []interface{}{}
+		for _, item := range value {
+			response = append(response, sortKeys(item))
+		}
+		return response
+	}
+
+	return value
+}
+
+//  Returns the value of the key, if the value is an object
+func lookup(value interface{}, key string) (interface{}, bool) {
+
+	object, _ := value.(yaml.MapSlice)
+	if metadata, ok := value.(Metadata); ok {
+		object = yaml.MapSlice(metadata)
+	}
+
+	for _, item := range object {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+//  Returns a copy of the object, with the value of the key replaced,
+//  or appended, if the object doesn't contain the key
+func replace(object yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+
+	response := append(yaml.MapSlice{}, object...)
+	for index, item := range response {
+		if fmt.Sprint(item.Key) == key {
+			response[index].Value = value
+			return response
+		}
+	}
+
+	return append(response, yaml.MapItem{Key: key, Value: value})
+}
+
+//  Returns a copy of the object, without the keys
+func without(object yaml.MapSlice, keys ...string) yaml.MapSlice {
+
+	response := yaml.MapSlice{}
+	for _, item := range object {
+		if !util.Contains(keys, fmt.Sprint(item.Key)) {
+			response = append(response, item)
+		}
+	}
+
+	return response
+}
+
+//  Whether the value is missing, or an empty list or object
+func isEmpty(value interface{}) bool {
+
+	switch value := value.(type) {
+	case nil:
+		return true
+	case yaml.MapSlice:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	}
+
+	return false
+}
+
+//  Returns the name of the file of a table or function,
+//  which is either a name, or an object with schema and name.
+//  Example: public_todos
+func objectFileName(identifier interface{}) string {
+
+	var parts []string
+	if object, ok := identifier.(yaml.MapSlice); ok {
+		for _, key := range []string{"schema", "dataset", "name"} {
+			if value, ok := lookup(object, key); ok {
+				parts = append(parts, fmt.Sprint(value))
+			}
+		}
+	} else if identifier != nil {
+		parts = append(parts, fmt.Sprint(identifier))
+	}
+
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(strings.Join(parts, "_"))
+}
+
+//  Decodes the next JSON value, with objects as yaml.MapSlice
+func decodeJSON(decoder *json.Decoder) (interface{}, error) {
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		response := yaml.MapSlice{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			response = append(response, yaml.MapItem{Key: key, Value: value})
+		}
+
+		//  Closing brace
+		_, err := decoder.Token()
+		return response, err
+
+	case json.Delim('['):
+		response := []interface{}{}
+		for decoder.More() {
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			response = append(response, value)
+		}
+
+		//  Closing bracket
+		_, err := decoder.Token()
+		return response, err
+	}
+
+	return token, nil
+}
+
+//  Encodes the value as JSON, with yaml.MapSlice as objects
+func encodeJSON(buffer *bytes.Buffer, value interface{}) error {
+
+	switch value := value.(type) {
+	case yaml.MapSlice:
+		buffer.WriteByte('{')
+		for index, item := range value {
+			if index > 0 {
+				buffer.WriteByte(',')
+			}
+
+			key, err := json.Marshal(fmt.Sprint(item.Key))
+			if err != nil {
+				return err
+			}
+			buffer.Write(key)
+			buffer.WriteByte(':')
+
+			if err := encodeJSON(buffer, item.Value); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+
+	case []interface{}:
+		buffer.WriteByte('[')
+		for index, item := range value {
+			if index > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := encodeJSON(buffer, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buffer.Write(data)
+	}
+
+	return nil
+}
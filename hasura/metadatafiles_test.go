@@ -0,0 +1,212 @@
+package hasura
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nhost/cli/nhost"
+)
+
+const testMetadata = `{
+	"version": 3,
+	"sources": [{
+		"name": "default",
+		"kind": "postgres",
+		"tables": [
+			{"table": {"schema": "public", "name": "todos"}, "object_relationships": [{"name": "user", "using": {"foreign_key_constraint_on": "user_id"}}]},
+			{"table": {"schema": "auth", "name": "users"}}
+		],
+		"configuration": {"connection_info": {"database_url": {"from_env": "HASURA_GRAPHQL_DATABASE_URL"}}}
+	}],
+	"actions": [{
+		"name": "login",
+		"definition": {"handler": "{{ACTIONS_URL}}/login", "output_type": "LoginOutput", "arguments": [{"name": "credentials", "type": "Credentials!"}], "type": "mutation", "kind": "synchronous"},
+		"permissions": [{"role": "public"}]
+	}, {
+		"name": "me",
+		"definition": {"handler": "{{ACTIONS_URL}}/me", "output_type": "[LoginOutput]", "type": "query"}
+	}],
+	"custom_types": {
+		"input_objects": [{"name": "Credentials", "fields": [{"name": "email", "type": "String!", "description": "Email of the \"user\""}, {"name": "password", "type": "String!"}]}],
+		"objects": [{"name": "LoginOutput", "fields": [{"name": "token", "type": "String"}], "relationships": [{"name": "user", "type": "object"}]}],
+		"enums": [{"name": "Role", "values": [{"value": "admin"}, {"value": "guest", "is_deprecated": true}]}],
+		"scalars": [{"name": "JWT", "description": "Signed token"}]
+	},
+	"remote_schemas": [{"name": "countries", "definition": {"url": "https://countries.trevorblades.com", "timeout_seconds": 60}}],
+	"api_limits": {"disabled": false}
+}`
+
+func TestMetadataFiles(t *testing.T) {
+
+	metadata, err := ParseMetadata(strings.NewReader(testMetadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "metadata")
+	if err := metadata.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{
+		"version.yaml",
+		"databases/databases.yaml",
+		"databases/default/tables/tables.yaml",
+		"databases/default/tables/public_todos.yaml",
+		"databases/default/tables/auth_users.yaml",
+		"actions.yaml",
+		"actions.graphql",
+		"allow_list.yaml",
+		"network.yaml",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Write() didn't create %s", name)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "databases", "default", "functions")); !os.IsNotExist(err) {
+		t.Error("Write() created functions of a source without any")
+	}
+
+	loaded, err := LoadMetadata(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err := DiffMetadata(metadata, loaded, "server", "local")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, item := range diffs {
+		t.Errorf("LoadMetadata() changed %s:\n%s", item.Path, item.Diff)
+	}
+
+	//  Signatures of actions are restored from actions.graphql
+	data, err := json.Marshal(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`"arguments":[{"name":"credentials","type":"Credentials!"}]`,
+		`"output_type":"[LoginOutput]"`,
+		`"type":"query"`,
+		`"relationships":[{"name":"user","type":"object"}]`,
+		`"description":"Email of the \"user\""`,
+		`"is_deprecated":true`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("LoadMetadata() = %s, want it to contain %s", data, want)
+		}
+	}
+
+	//  Changes are reported against the file containing them
+	changed, err := ParseMetadata(strings.NewReader(strings.Replace(testMetadata, `"user_id"`, `"owner_id"`, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err = DiffMetadata(loaded, changed, "local", "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diffs) != 1 || diffs[0].Path != "databases/default/tables/public_todos.yaml" {
+		t.Fatalf("DiffMetadata() = %v, want a change of public_todos.yaml", diffs)
+	}
+
+	if !strings.Contains(diffs[0].Diff, "-    foreign_key_constraint_on: user_id\n+    foreign_key_constraint_on: owner_id\n") {
+		t.Errorf("DiffMetadata() = %s", diffs[0].Diff)
+	}
+}
+
+func TestParseActionsSchema(t *testing.T) {
+
+	//  Formatted the way the Hasura CLI writes it
+	sdl := `type Mutation {
+  # Signs the user in
+  login (
+    credentials: Credentials!
+  ): LoginOutput
+}
+
+"""
+  Login details
+"""
+input Credentials {
+  email : String!
+  password : String! = "secret" @deprecated(reason: "unused")
+}
+
+enum Role {
+  admin
+  guest @deprecated
+}
+`
+
+	schema, err := parseActionsSchema(sdl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output, _ := lookup(schema.actions["login"], "output_type"); output != "LoginOutput" {
+		t.Errorf("output_type of login = %v, want LoginOutput", output)
+	}
+
+	inputs := schema.types["input_objects"]
+	if len(inputs) != 1 {
+		t.Fatalf("input objects = %v, want Credentials", inputs)
+	}
+
+	if description, _ := lookup(inputs[0], "description"); description != "Login details" {
+		t.Errorf("description of Credentials = %q, want %q", description, "Login details")
+	}
+
+	fields, _ := lookup(inputs[0], "fields")
+	if list, _ := fields.([]interface{}); len(list) != 2 {
+		t.Errorf("fields of Credentials = %v, want 2 of them", fields)
+	}
+
+	if len(schema.types["enums"]) != 1 {
+		t.Errorf("enums = %v, want Role", schema.types["enums"])
+	}
+
+	for _, invalid := range []string{`type Mutation {`, `input Credentials { email String }`, `union Result = A | B`, `"""unterminated`} {
+		if _, err := parseActionsSchema(invalid); err == nil {
+			t.Errorf("parseActionsSchema(%q) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestInconsistentObject(t *testing.T) {
+
+	nhost.DATABASE = "default"
+
+	tests := map[string][2]string{
+		`{"type": "table", "definition": {"schema": "public", "name": "todos"}}`:                                                     {"public.todos", "databases/default/tables/public_todos.yaml"},
+		`{"type": "object_relation", "definition": {"name": "user", "table": {"schema": "public", "name": "todos"}}}`:                {"public.todos user", "databases/default/tables/public_todos.yaml"},
+		`{"type": "select_permission", "definition": {"role": "user", "table": {"schema": "public", "name": "todos"}}}`:              {"public.todos for role user", "databases/default/tables/public_todos.yaml"},
+		`{"type": "function", "definition": {"schema": "public", "name": "search", "source": "other"}}`:                              {"public.search", "databases/other/functions/public_search.yaml"},
+		`{"type": "source", "definition": "default"}`:                                                                                {"default", "databases/databases.yaml"},
+		`{"type": "remote_schema", "name": "remote_schema countries", "definition": {"definition": {"url": "https://example.com"}}}`: {"remote_schema countries", "remote_schemas.yaml"},
+	}
+
+	for payload, want := range tests {
+		var object InconsistentObject
+		if err := json.Unmarshal([]byte(payload), &object); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := object.Object(); got != want[0] {
+			t.Errorf("Object() of %s = %q, want %q", payload, got, want[0])
+		}
+
+		if got := object.File(); got != want[1] {
+			t.Errorf("File() of %s = %q, want %q", payload, got, want[1])
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package hasura
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nhost/cli/nhost"
+	"gopkg.in/yaml.v2"
+)
+
+//  Client performs metadata operations directly through Hasura's metadata API
+var _ CommonMetadataOperations = &Client{}
+
+//  Error returned by the metadata API.
+//  If the metadata is inconsistent, it contains the objects which failed.
+type MetadataError struct {
+	Response
+	Inconsistencies []InconsistentObject
+}
+
+func (e *MetadataError) Error() string {
+	return e.Response.Error
+}
+
+//  Exports the metadata of the server as JSON
+func (c *Client) ExportMetadata() (io.Reader, error) {
+
+	log.Debug("Exporting metadata")
+
+	return c.metadataRequest(RequestBody{
+		Type: "export_metadata",
+		Args: map[string]string{},
+	})
+}
+
+//  Resets the metadata of the server, untracking everything
+func (c *Client) ClearMetadata() (io.Reader, error) {
+
+	log.Debug("Clearing metadata")
+
+	return c.metadataRequest(RequestBody{
+		Type: "clear_metadata",
+		Args: map[string]string{},
+	})
+}
+
+//  Reloads the metadata, picking up changes of the database and remote schemas
+func (c *Client) ReloadMetadata() (io.Reader, error) {
+
+	log.Debug("Reloading metadata")
+
+	return c.metadataRequest(RequestBody{
+		Type: "reload_metadata",
+		Args: map[string]string{},
+	})
+}
+
+//  Drops all inconsistent objects from the metadata
+func (c *Client) DropInconsistentMetadata() (io.Reader, error) {
+
+	log.Debug("Dropping inconsistent metadata")
+
+	return c.metadataRequest(RequestBody{
+		Type: "drop_inconsistent_metadata",
+		Args: map[string]string{},
+	})
+}
+
+//  Replaces the metadata of the server with the supplied JSON
+func (c *Client) ReplaceMetadata(metadata io.Reader) (io.Reader, error) {
+
+	var payload interface{}
+	if err := json.NewDecoder(metadata).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decoding metadata: %w", err)
+	}
+
+	log.Debug("Replacing metadata")
+
+	return c.metadataRequest(RequestBody{
+		Type: "replace_metadata",
+		Args: payload,
+	})
+}
+
+//  Replaces the metadata of the server, and reports inconsistent objects.
+//  Unless inconsistencies are allowed, nothing is replaced if there are any,
+//  and a *MetadataError listing them is returned.
+func (c *Client) ApplyMetadata(metadata Metadata, allowInconsistent bool) (*V2ReplaceMetadataResponse, error) {
+
+	log.Debug("Applying metadata")
+
+	body, err := c.metadataRequest(RequestBody{
+		Type:    "replace_metadata",
+		Version: 2,
+		Args: V2ReplaceMetadataArgs{
+			AllowInconsistentMetadata: allowInconsistent,
+			Metadata:                  metadata,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response V2ReplaceMetadataResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &response, nil
+}
+
+//  Lists the objects of the metadata which Hasura failed to apply
+func (c *Client) GetInconsistentMetadata() (*InconsistentMetadataResponse, error) {
+
+	body, err := c.GetInconsistentMetadataReader()
+	if err != nil {
+		return nil, err
+	}
+
+	var response InconsistentMetadataResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &response, nil
+}
+
+func (c *Client) GetInconsistentMetadataReader() (io.Reader, error) {
+
+	log.Debug("Fetching inconsistent metadata")
+
+	return c.metadataRequest(RequestBody{
+		Type: "get_inconsistent_metadata",
+		Args: map[string]string{},
+	})
+}
+
+//  Sends the request to the metadata API, and returns the body of the response
+func (c *Client) metadataRequest(request RequestBody) (*bytes.Buffer, error) {
+
+	body, err := request.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Request(body, "/v1/metadata")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	response := new(bytes.Buffer)
+	if _, err := response.ReadFrom(resp.Body); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Response
+			Internal json.RawMessage `json:"internal"`
+		}
+
+		if err := json.Unmarshal(response.Bytes(), &failure); err != nil || failure.Error == "" {
+			return nil, errors.New(strings.TrimSpace(response.String()))
+		}
+
+		//  Inconsistencies are only listed for inconsistent metadata,
+		//  other errors may have different internal details
+		var inconsistencies []InconsistentObject
+		json.Unmarshal(failure.Internal, &inconsistencies)
+
+		return nil, &MetadataError{
+			Response:        failure.Response,
+			Inconsistencies: inconsistencies,
+		}
+	}
+
+	return response, nil
+}
+
+//  Returns a human readable name of the object. Example: public.todos
+func (o *InconsistentObject) Object() string {
+
+	var definition struct {
+		Name   interface{} `json:"name"`
+		Schema string      `json:"schema"`
+		Table  interface{} `json:"table"`
+		Role   string      `json:"role"`
+	}
+
+	//  Definitions of some objects, like sources, are plain strings
+	if err := json.Unmarshal(o.Definition, &definition); err != nil {
+		var name string
+		if json.Unmarshal(o.Definition, &name) == nil && name != "" {
+			return name
+		}
+		return o.Name
+	}
+
+	var parts []string
+	if table := qualifiedName(definition.Table); table != "" {
+		parts = append(parts, table)
+	}
+
+	name := qualifiedName(definition.Name)
+	if definition.Schema != "" && name != "" {
+		name = definition.Schema + "." + name
+	}
+	if name != "" {
+		parts = append(parts, name)
+	}
+
+	if definition.Role != "" {
+		parts = append(parts, "for role "+definition.Role)
+	}
+
+	if len(parts) == 0 {
+		return o.Name
+	}
+
+	return strings.Join(parts, " ")
+}
+
+//  Formats a table or function, which is either a name,
+//  or an object with schema and name
+func qualifiedName(value interface{}) string {
+
+	switch value := value.(type) {
+	case string:
+		return value
+	case map[string]interface{}:
+		name, _ := value["name"].(string)
+		if schema, ok := value["schema"].(string); ok && schema != "" && name != "" {
+			return schema + "." + name
+		}
+		return name
+	}
+
+	return ""
+}
+
+//  Groups the inconsistent objects by their types, sorted by types
+func GroupInconsistencies(objects []InconsistentObject) ([]string, map[string][]InconsistentObject) {
+
+	var types []string
+	response := make(map[string][]InconsistentObject)
+
+	for _, item := range objects {
+		if _, ok := response[item.Type]; !ok {
+			types = append(types, item.Type)
+		}
+		response[item.Type] = append(response[item.Type], item)
+	}
+
+	sort.Strings(types)
+	return types, response
+}
+
+//  Returns a report of the inconsistent objects, grouped by their types,
+//  with the object and the reason of every one of them
+func InconsistencyReport(objects []InconsistentObject) string {
+
+	var response bytes.Buffer
+	types, groups := GroupInconsistencies(objects)
+
+	writer := tabwriter.NewWriter(&response, 1, 1, 2, ' ', 0)
+	for _, kind := range types {
+		fmt.Fprintf(writer, "\n%s\n", strings.ReplaceAll(kind, "_", " "))
+		for _, item := range groups[kind] {
+			fmt.Fprintf(writer, "  %s\t%s\n", item.Object(), item.Reason)
+		}
+	}
+	writer.Flush()
+
+	return response.String()
+}
+
+//  Files of the metadata directory defining objects, which aren't part of tables or functions
+var inconsistentObjectFiles = map[string]string{
+	"source":                   "databases/databases.yaml",
+	"remote_schema":            "remote_schemas.yaml",
+	"remote_schema_permission": "remote_schemas.yaml",
+	"action":                   "actions.yaml",
+	"action_permission":        "actions.yaml",
+	"custom_types":             "actions.graphql",
+	"cron_trigger":             "cron_triggers.yaml",
+	"inherited_role":           "inherited_roles.yaml",
+	"rest_endpoint":            "rest_endpoints.yaml",
+	"query_collection":         "query_collections.yaml",
+	"allowlist":                "allow_list.yaml",
+}
+
+//  Returns the path of the file defining the object, relative to the metadata directory.
+//  Returns an empty string, if it's unknown.
+//  Example: databases/default/tables/public_todos.yaml
+func (o *InconsistentObject) File() string {
+
+	if file, ok := inconsistentObjectFiles[o.Type]; ok {
+		return file
+	}
+
+	definition, err := decodeJSON(json.NewDecoder(bytes.NewReader(o.Definition)))
+	if err != nil {
+		return ""
+	}
+
+	source := nhost.DATABASE
+	if value, ok := lookup(definition, "source"); ok {
+		source = fmt.Sprint(value)
+	}
+
+	//  Objects of tables, like relationships and permissions, mention their table,
+	//  while definitions of tables and functions may be their names themselves
+	kind, identifier := "", interface{}(nil)
+	if value, ok := lookup(definition, "table"); ok {
+		kind, identifier = "tables", value
+	} else if value, ok := lookup(definition, "function"); ok {
+		kind, identifier = "functions", value
+	} else if o.Type == "table" || o.Type == "function" {
+		kind, identifier = o.Type+"s", definition
+	}
+
+	if _, ok := identifier.(yaml.MapSlice); !ok {
+		return ""
+	}
+
+	return filepath.ToSlash(filepath.Join("databases", source, kind, objectFileName(identifier)+".yaml"))
+}
@@ -0,0 +1,347 @@
+package hasura
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/nhost/cli/nhost"
+	"github.com/sirupsen/logrus"
+)
+
+//  Table recording the migrations applied to the database,
+//  in the format of the Hasura CLI
+const MIGRATIONS_TABLE = "hdb_catalog.schema_migrations"
+
+//  Directories of migrations. Example: 1637000000000_init
+var migrationPattern = regexp.MustCompile(`^(\d+)_(.+)$`)
+
+//  Returns the local migrations of the source, ordered by their versions
+func LocalMigrations(source string) ([]Migration, error) {
+
+	var response []Migration
+
+	files, err := ioutil.ReadDir(filepath.Join(nhost.MIGRATIONS_DIR, source))
+	if os.IsNotExist(err) {
+		return response, nil
+	} else if err != nil {
+		return response, err
+	}
+
+	for _, file := range files {
+
+		match := migrationPattern.FindStringSubmatch(file.Name())
+		if !file.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return response, fmt.Errorf("invalid version of migration %s: %w", file.Name(), err)
+		}
+
+		response = append(response, Migration{
+			Name:     match[2],
+			Version:  version,
+			Location: filepath.Join(nhost.MIGRATIONS_DIR, source, file.Name()),
+		})
+	}
+
+	sort.Slice(response, func(i, j int) bool { return response[i].Version < response[j].Version })
+
+	return response, nil
+}
+
+//  Reads the up.sql or down.sql file of the migration.
+//  Migrations without the file have nothing to execute.
+func (m *Migration) SQL(file string) (string, error) {
+
+	data, err := ioutil.ReadFile(filepath.Join(m.Location, file))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+
+	return string(data), err
+}
+
+//  Returns the versions of migrations applied to the database of the source.
+//
+//  Includes the ones recorded by the Hasura CLI in Hasura's catalog,
+//  which is where the console records migrations created in it.
+func (c *Client) AppliedMigrations(source string) (map[int64]bool, error) {
+
+	log.WithField("source", source).Debug("Fetching applied migrations")
+
+	response := make(map[int64]bool)
+
+	//  The table is only created once a migration is applied,
+	//  and fetching versions should never modify the database
+	result, err := c.runSQL(source, fmt.Sprintf("SELECT to_regclass(%s) IS NOT NULL;", quoteLiteral(MIGRATIONS_TABLE)))
+	if err != nil {
+		return response, err
+	}
+
+	if len(result.Rows) > 1 && fmt.Sprint(result.Rows[1][0]) == "t" {
+
+		result, err := c.runSQL(source, fmt.Sprintf("SELECT version FROM %s WHERE NOT dirty;", MIGRATIONS_TABLE))
+		if err != nil {
+			return response, err
+		}
+
+		//  The first row contains column names
+		for index, row := range result.Rows {
+			if index == 0 || len(row) != 1 {
+				continue
+			}
+			if version, err := strconv.ParseInt(fmt.Sprint(row[0]), 10, 64); err == nil {
+				response[version] = true
+			}
+		}
+	}
+
+	_, migrations, err := c.cliState()
+	if err != nil {
+		log.WithField("source", source).Debug("Failed to fetch migrations of the Hasura CLI: ", err)
+		return response, nil
+	}
+
+	for version, dirty := range migrations[source] {
+		if parsed, err := strconv.ParseInt(version, 10, 64); err == nil && !dirty {
+			response[parsed] = true
+		}
+	}
+
+	return response, nil
+}
+
+//  Applies the migrations of the source, which haven't been applied yet,
+//  in the order of their versions. Returns the applied ones.
+func (c *Client) ApplyMigrations(source string) ([]Migration, error) {
+
+	var response []Migration
+
+	migrations, err := LocalMigrations(source)
+	if err != nil || len(migrations) == 0 {
+		return response, err
+	}
+
+	applied, err := c.AppliedMigrations(source)
+	if err != nil {
+		return response, err
+	}
+
+	for _, item := range migrations {
+		if applied[item.Version] {
+			continue
+		}
+
+		if err := c.ApplyMigration(source, item); err != nil {
+			return response, err
+		}
+
+		response = append(response, item)
+	}
+
+	return response, nil
+}
+
+//  Executes the up.sql of the migration, and records it as applied, in a single transaction.
+//  If the migration fails, neither it's changes, nor the record, are saved.
+//  It's recorded for the Hasura CLI too, so that the console doesn't apply it again.
+func (c *Client) ApplyMigration(source string, migration Migration) error {
+
+	log.WithFields(logrus.Fields{
+		"source":  source,
+		"version": migration.Version,
+	}).Debug("Applying migration")
+
+	sql, err := migration.SQL("up.sql")
+	if err != nil {
+		return err
+	}
+
+	//  The migration may end without a semicolon, or with a comment
+	if _, err := c.runSQL(source, fmt.Sprintf("%s\n;\n%s", sql, recordMigrationSQL(migration.Version))); err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return c.updateCLIMigrations(source, map[int64]bool{migration.Version: true})
+}
+
+//  Executes the down.sql of the migration, and forgets it, in a single transaction
+func (c *Client) RollbackMigration(source string, migration Migration) error {
+
+	log.WithFields(logrus.Fields{
+		"source":  source,
+		"version": migration.Version,
+	}).Debug("Rolling back migration")
+
+	sql, err := migration.SQL("down.sql")
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.runSQL(source, fmt.Sprintf("%s\n;\n%s", sql, forgetMigrationSQL(migration.Version))); err != nil {
+		return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return c.updateCLIMigrations(source, map[int64]bool{migration.Version: false})
+}
+
+//  Records the versions as applied, or forgets them, without executing their migrations.
+//  All of them are recorded in a single transaction, so that none is left behind.
+func (c *Client) MarkMigrations(source string, versions map[int64]bool) error {
+
+	log.WithField("source", source).Debug("Marking migrations: ", versions)
+
+	if len(versions) == 0 {
+		return nil
+	}
+
+	var sorted []int64
+	for version := range versions {
+		sorted = append(sorted, version)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sql string
+	for _, version := range sorted {
+		if versions[version] {
+			sql += recordMigrationSQL(version) + "\n"
+		} else {
+			sql += forgetMigrationSQL(version) + "\n"
+		}
+	}
+
+	if _, err := c.runSQL(source, sql); err != nil {
+		return err
+	}
+
+	return c.updateCLIMigrations(source, versions)
+}
+
+//  Forgets all applied migrations of the source,
+//  so that all of them are applied again
+func (c *Client) ClearMigration(source string) error {
+
+	log.WithField("source", source).Debug("Clearing migration")
+
+	if _, err := c.runSQL(source, fmt.Sprintf("DROP TABLE IF EXISTS %s;", MIGRATIONS_TABLE)); err != nil {
+		return err
+	}
+
+	return c.updateCLIMigrations(source, nil)
+}
+
+//  Returns the SQL recording the version as applied
+func recordMigrationSQL(version int64) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL);
+INSERT INTO %s (version, dirty) VALUES (%d, false) ON CONFLICT (version) DO UPDATE SET dirty = false;`,
+		MIGRATIONS_TABLE, MIGRATIONS_TABLE, version)
+}
+
+//  Returns the SQL forgetting the applied version
+func forgetMigrationSQL(version int64) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL);
+DELETE FROM %s WHERE version = %d;`, MIGRATIONS_TABLE, MIGRATIONS_TABLE, version)
+}
+
+//  Fetches the state of the Hasura CLI from Hasura's catalog.
+//  Returns the whole state, along with applied migrations, by their sources and versions.
+func (c *Client) cliState() (map[string]json.RawMessage, map[string]map[string]bool, error) {
+
+	body, err := c.metadataRequest(RequestBody{
+		Type: "get_catalog_state",
+		Args: map[string]string{},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var response struct {
+		CLIState map[string]json.RawMessage `json:"cli_state"`
+	}
+
+	if err := json.Unmarshal(body.Bytes(), &response); err != nil {
+		return nil, nil, err
+	}
+
+	if response.CLIState == nil {
+		response.CLIState = make(map[string]json.RawMessage)
+	}
+
+	migrations := make(map[string]map[string]bool)
+	if data, ok := response.CLIState["migrations"]; ok {
+		if err := json.Unmarshal(data, &migrations); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return response.CLIState, migrations, nil
+}
+
+//  Records the versions as applied in the state of the Hasura CLI, or forgets them,
+//  or all of them, if none are mentioned. So that the Hasura CLI, which deploys
+//  migrations, and runs the console, agrees with the recorded migrations.
+//  Servers without a catalog state are left as they are.
+func (c *Client) updateCLIMigrations(source string, versions map[int64]bool) error {
+
+	state, migrations, err := c.cliState()
+	if err != nil {
+		log.WithField("source", source).Debug("Failed to fetch migrations of the Hasura CLI: ", err)
+		return nil
+	}
+
+	recorded := migrations[source]
+	if recorded == nil {
+		recorded = make(map[string]bool)
+	}
+
+	changed := false
+	for version, applied := range versions {
+		_, ok := recorded[fmt.Sprint(version)]
+		if applied {
+			changed = changed || !ok || recorded[fmt.Sprint(version)]
+			recorded[fmt.Sprint(version)] = false
+		} else if ok {
+			changed = true
+			delete(recorded, fmt.Sprint(version))
+		}
+	}
+
+	if len(versions) == 0 {
+		changed = len(recorded) > 0
+		recorded = nil
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if len(recorded) == 0 {
+		delete(migrations, source)
+	} else {
+		migrations[source] = recorded
+	}
+
+	data, err := json.Marshal(migrations)
+	if err != nil {
+		return err
+	}
+	state["migrations"] = data
+
+	_, err = c.metadataRequest(RequestBody{
+		Type: "set_catalog_state",
+		Args: map[string]interface{}{
+			"type":  "cli",
+			"state": state,
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,141 @@
+package hasura
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nhost/cli/nhost"
+)
+
+func TestApplyMigrations(t *testing.T) {
+
+	defer func(dir string) { nhost.MIGRATIONS_DIR = dir }(nhost.MIGRATIONS_DIR)
+	nhost.MIGRATIONS_DIR = t.TempDir()
+
+	for name, up := range map[string]string{
+		"1_init":    "CREATE TABLE init ();",
+		"2_console": "CREATE TABLE console ();",
+		"3_todos":   "CREATE TABLE todos (); -- without a trailing newline",
+		"4_broken":  "FAIL",
+		"5_after":   "CREATE TABLE after ();",
+	} {
+		dir := filepath.Join(nhost.MIGRATIONS_DIR, "default", name)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "up.sql"), []byte(up), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		queries []string
+		state   map[string]interface{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Type string                 `json:"type"`
+			Args map[string]interface{} `json:"args"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch body.Type {
+		case "run_sql":
+			sql := fmt.Sprint(body.Args["sql"])
+			queries = append(queries, sql)
+
+			switch {
+			case strings.Contains(sql, "to_regclass"):
+				fmt.Fprint(w, `{"result_type": "TuplesOk", "result": [["?column?"], ["t"]]}`)
+			case strings.HasPrefix(sql, "SELECT version"):
+				fmt.Fprint(w, `{"result_type": "TuplesOk", "result": [["version"], ["1"]]}`)
+			case strings.HasPrefix(sql, "FAIL"):
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "query execution failed", "code": "postgres-error", "internal": {"error": {"message": "syntax error at or near \"FAIL\""}}}`)
+			default:
+				fmt.Fprint(w, `{"result_type": "CommandOk", "result": null}`)
+			}
+
+		//  Migrations created in the console are recorded by the Hasura CLI
+		case "get_catalog_state":
+			fmt.Fprint(w, `{"id": "1", "cli_state": {"migrations": {"default": {"2": false}, "other": {"7": false}}, "settings": {"migration_mode": "true"}}}`)
+		case "set_catalog_state":
+			state, _ = body.Args["state"].(map[string]interface{})
+			fmt.Fprint(w, `{"message": "success"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := Client{Endpoint: server.URL, Client: &http.Client{}}
+
+	applied, err := client.ApplyMigrations("default")
+	if err == nil || !strings.Contains(err.Error(), "4_broken") || !strings.Contains(err.Error(), "syntax error") {
+		t.Errorf("ApplyMigrations() error = %v, want the failure of 4_broken", err)
+	}
+
+	if len(applied) != 1 || applied[0].Version != 3 || applied[0].Name != "todos" {
+		t.Fatalf("ApplyMigrations() = %v, want only 3_todos", applied)
+	}
+
+	//  Migrations are applied along with their records, and never after a failure
+	var executed []string
+	for _, sql := range queries {
+		for _, table := range []string{"init", "console", "todos", "after"} {
+			if strings.Contains(sql, fmt.Sprintf("CREATE TABLE %s ", table)) {
+				executed = append(executed, table)
+			}
+		}
+	}
+
+	if strings.Join(executed, ",") != "todos" {
+		t.Errorf("ApplyMigrations() executed %v, want only todos", executed)
+	}
+
+	if !strings.Contains(queries[len(queries)-2], "-- without a trailing newline\n;\n") || !strings.Contains(queries[len(queries)-2], "VALUES (3, false)") {
+		t.Errorf("ApplyMigrations() ran %q", queries[len(queries)-2])
+	}
+
+	//  Applied migrations are recorded for the CLI, so that the console doesn't apply them again
+	data, _ := json.Marshal(state["migrations"])
+	if string(data) != `{"default":{"2":false,"3":false},"other":{"7":false}}` {
+		t.Errorf("ApplyMigrations() set the migrations of the CLI to %s", data)
+	}
+
+	//  Clearing forgets migrations of the source only, and keeps other state of the CLI
+	if err := client.ClearMigration("default"); err != nil {
+		t.Fatal(err)
+	}
+
+	if last := queries[len(queries)-1]; last != "DROP TABLE IF EXISTS hdb_catalog.schema_migrations;" {
+		t.Errorf("ClearMigration() ran %q", last)
+	}
+
+	data, _ = json.Marshal(state)
+	if string(data) != `{"migrations":{"other":{"7":false}},"settings":{"migration_mode":"true"}}` {
+		t.Errorf("ClearMigration() set the state of the CLI to %s", data)
+	}
+
+	//  Migrations recorded without being executed are recorded for the CLI too
+	if err := client.MarkMigrations("default", map[int64]bool{3: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if last := queries[len(queries)-1]; !strings.Contains(last, "VALUES (3, false)") {
+		t.Errorf("MarkMigrations() ran %q", last)
+	}
+
+	data, _ = json.Marshal(state["migrations"])
+	if string(data) != `{"default":{"2":false,"3":false},"other":{"7":false}}` {
+		t.Errorf("MarkMigrations() set the migrations of the CLI to %s", data)
+	}
+}
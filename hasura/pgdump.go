@@ -3,6 +3,7 @@ package hasura
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 )
@@ -55,5 +56,36 @@ func (c *Client) PGDump(options []string) ([]byte, error) {
 	defer resp.Body.Close()
 
 	response, err = ioutil.ReadAll(resp.Body)
-	return response, err
+	if err != nil {
+		return response, err
+	}
+
+	//	Failures are returned as JSON, instead of the dump
+	if resp.StatusCode != http.StatusOK {
+		var failure Response
+		if err := json.Unmarshal(response, &failure); err != nil || failure.Error == "" {
+			return nil, errors.New(string(response))
+		}
+		return nil, errors.New(failure.Error)
+	}
+
+	return response, nil
+}
+
+//  Dumps the schema of the app's database, without Hasura's catalog,
+//  and the schemas of auth and storage, which are managed by their services
+func (c *Client) DumpSchema() (string, error) {
+
+	schemas, err := c.GetSchemas()
+	if err != nil || len(schemas) == 0 {
+		return "", err
+	}
+
+	options := []string{"--schema-only", "--no-owner", "--no-acl"}
+	for _, schema := range schemas {
+		options = append(options, "--schema", schema)
+	}
+
+	response, err := c.PGDump(options)
+	return string(response), err
 }
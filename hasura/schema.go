@@ -0,0 +1,741 @@
+package hasura
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//  Difference between two schemas, as SQL statements
+type SchemaDiff struct {
+
+	//  Statements turning the first schema into the second one
+	Up string
+
+	//  Statements reverting them
+	Down string
+
+	//  Tables created by the up statements. Example: public.todos
+	Tables []string
+}
+
+//  Object of a schema, created by a statement of pg_dump
+type schemaObject struct {
+	kind      string
+	name      string
+	statement string
+
+	//  Names of columns and constraints of tables, in order, and their definitions
+	names       []string
+	definitions map[string]string
+}
+
+//  Statements of pg_dump, which don't create objects of the app
+var ignoredStatement = regexp.MustCompile(`^(SET |SELECT pg_catalog\.set_config|CREATE SCHEMA public$|COMMENT ON SCHEMA public )`)
+
+//  Statements creating objects, and the kinds of those objects.
+//  The first group of every expression is the name of the object.
+var schemaStatements = []struct {
+	kind       string
+	expression *regexp.Regexp
+}{
+	{"schema", regexp.MustCompile(`^CREATE SCHEMA (\S+)`)},
+	{"extension", regexp.MustCompile(`^CREATE EXTENSION (?:IF NOT EXISTS )?(\S+)`)},
+	{"type", regexp.MustCompile(`^CREATE TYPE (\S+)`)},
+	{"domain", regexp.MustCompile(`^CREATE DOMAIN (\S+)`)},
+	{"table", regexp.MustCompile(`^CREATE (?:UNLOGGED )?TABLE (\S+) \(`)},
+	{"function", regexp.MustCompile(`^CREATE (?:OR REPLACE )?FUNCTION (\S+?\()`)},
+	{"procedure", regexp.MustCompile(`^CREATE (?:OR REPLACE )?PROCEDURE (\S+?\()`)},
+	{"view", regexp.MustCompile(`^CREATE (?:OR REPLACE )?VIEW (\S+)`)},
+	{"materialized view", regexp.MustCompile(`^CREATE MATERIALIZED VIEW (\S+)`)},
+	{"sequence", regexp.MustCompile(`^CREATE SEQUENCE (\S+)`)},
+	{"sequence owner", regexp.MustCompile(`^ALTER SEQUENCE (\S+) OWNED BY`)},
+	{"default", regexp.MustCompile(`^ALTER TABLE (?:ONLY )?(\S+ ALTER COLUMN \S+) SET DEFAULT`)},
+	{"constraint", regexp.MustCompile(`^ALTER TABLE (?:ONLY )?(\S+\s+ADD CONSTRAINT \S+)`)},
+	{"index", regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX (\S+ ON (?:ONLY )?\S+)`)},
+	{"trigger", regexp.MustCompile(`^CREATE (?:CONSTRAINT )?TRIGGER (\S+ (?s:.*?) ON \S+)`)},
+	{"comment", regexp.MustCompile(`^COMMENT ON ((?s:.*?)) IS `)},
+}
+
+//  Compares two schema-only dumps of pg_dump, and returns
+//  the statements adding the objects of the second schema to the first one.
+//
+//  Objects are compared by the statements creating them.
+//  Changed tables are altered column by column, to keep their data,
+//  changed functions and views are replaced, and values are added to changed enums.
+//  Changed indexes, triggers and constraints, which nothing depends on, are dropped
+//  and created again. Other changes, like removed enum values, have to be made manually.
+//
+//  Objects, columns and constraints, which only exist in the first schema,
+//  like the ones of migrations which haven't been deployed yet, are never dropped.
+func DiffSchema(from, to string) SchemaDiff {
+
+	fromObjects, toObjects := parseSchema(from), parseSchema(to)
+	shared := sharedObjects(fromObjects, toObjects)
+
+	return SchemaDiff{
+		Up:     migrateSchema(shared, toObjects),
+		Down:   migrateSchema(toObjects, shared),
+		Tables: createdTables(fromObjects, toObjects),
+	}
+}
+
+//  Returns the objects, which also exist among the other objects,
+//  with only the columns and constraints of tables, which exist in both
+func sharedObjects(objects, others []schemaObject) []schemaObject {
+
+	var response []schemaObject
+
+	index := indexObjects(others)
+	for _, item := range objects {
+
+		other, ok := index[item.key()]
+		if !ok {
+			continue
+		}
+
+		if item.kind == "table" {
+			names, definitions := item.names, item.definitions
+			item.names, item.definitions = nil, make(map[string]string)
+			for _, name := range names {
+				if _, ok := other.definitions[name]; ok {
+					item.names = append(item.names, name)
+					item.definitions[name] = definitions[name]
+				}
+			}
+		}
+
+		response = append(response, item)
+	}
+
+	return response
+}
+
+//  Returns the statements turning the objects of one schema into another
+func migrateSchema(from, to []schemaObject) string {
+
+	existing := indexObjects(from)
+	wanted := indexObjects(to)
+
+	var statements []string
+
+	//  Drop objects, in the reverse order of their creation,
+	//  so that dependent objects are dropped first
+	for index := len(from) - 1; index >= 0; index-- {
+
+		item := from[index]
+		replacement, ok := wanted[item.key()]
+
+		if ok && (replacement.statement == item.statement || !item.replaceable()) {
+			continue
+		}
+
+		statements = append(statements, item.drop())
+	}
+
+	//  Create objects in their order in the dump, which creates dependencies first
+	for _, item := range to {
+
+		previous, ok := existing[item.key()]
+
+		switch {
+		case !ok:
+			statements = append(statements, item.statement)
+		case previous.statement == item.statement:
+			continue
+		case item.kind == "table":
+			statements = append(statements, alterTable(previous, item)...)
+		case item.kind == "function", item.kind == "procedure", item.kind == "view":
+			statements = append(statements, strings.Replace(item.statement, "CREATE ", "CREATE OR REPLACE ", 1))
+		case item.alterable(), item.replaceable():
+
+			//  Replaceable objects have been dropped above,
+			//  the others are changed by running their statement again
+			statements = append(statements, item.statement)
+		default:
+
+			//  Columns of the database may depend on them,
+			//  so they can't be dropped and created again
+			if added, ok := addEnumValues(previous, item); ok {
+				statements = append(statements, added...)
+			} else {
+				statements = append(statements, manualStatement(item.statement))
+			}
+		}
+	}
+
+	if len(statements) == 0 {
+		return ""
+	}
+
+	return strings.Join(statements, ";\n\n") + ";\n"
+}
+
+//  Returns the tables, which only exist in the second schema
+func createdTables(from, to []schemaObject) []string {
+
+	var response []string
+
+	existing := indexObjects(from)
+	for _, item := range to {
+		if _, ok := existing[item.key()]; !ok && item.kind == "table" {
+			response = append(response, strings.ReplaceAll(item.name, `"`, ""))
+		}
+	}
+
+	return response
+}
+
+//  Returns the objects by their keys
+func indexObjects(objects []schemaObject) map[string]schemaObject {
+
+	response := make(map[string]schemaObject)
+	for _, item := range objects {
+		response[item.key()] = item
+	}
+
+	return response
+}
+
+//  Identifies the object among all objects of a schema
+func (o *schemaObject) key() string {
+	return o.kind + " " + o.name
+}
+
+//  Objects, which are altered by running their statement again,
+//  or by replacing them, instead of dropping them
+func (o *schemaObject) alterable() bool {
+	switch o.kind {
+	case "table", "function", "procedure", "view", "sequence owner", "default", "comment":
+		return true
+	}
+	return false
+}
+
+//  Objects, which can be dropped and created again when they change,
+//  since no other objects depend on them.
+//  Foreign keys may depend on primary keys and unique constraints.
+func (o *schemaObject) replaceable() bool {
+	switch o.kind {
+	case "index", "trigger":
+		return true
+	case "constraint":
+		return !strings.Contains(o.statement, " PRIMARY KEY ") && !strings.Contains(o.statement, " UNIQUE ")
+	}
+	return false
+}
+
+//  Returns the statement dropping the object
+func (o *schemaObject) drop() string {
+
+	switch o.kind {
+	case "schema", "extension", "type", "domain", "table", "view", "materialized view", "sequence":
+		return fmt.Sprintf("DROP %s IF EXISTS %s", strings.ToUpper(o.kind), o.name)
+	case "function", "procedure":
+		return fmt.Sprintf("DROP %s IF EXISTS %s", strings.ToUpper(o.kind), routineSignature(o.statement))
+	case "default":
+		return fmt.Sprintf("ALTER TABLE %s DROP DEFAULT", o.name)
+	case "constraint":
+		table, constraint := splitAround(o.name, "ADD CONSTRAINT")
+		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", table, constraint)
+	case "index":
+		index, table := splitAround(o.name, "ON")
+		return fmt.Sprintf("DROP INDEX IF EXISTS %s", qualify(index, strings.TrimPrefix(table, "ONLY ")))
+	case "trigger":
+		trigger := strings.Fields(o.name)[0]
+		table := strings.Fields(o.name)[len(strings.Fields(o.name))-1]
+		return fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", trigger, table)
+	case "comment":
+		return fmt.Sprintf("COMMENT ON %s IS NULL", o.name)
+	case "sequence owner":
+		return fmt.Sprintf("ALTER SEQUENCE %s OWNED BY NONE", o.name)
+	}
+
+	return manualStatement(o.statement)
+}
+
+//  Returns the statement as a comment, for changes which have to be made manually
+func manualStatement(statement string) string {
+	return "-- Revert manually: " + strings.ReplaceAll(statement, "\n", "\n-- ")
+}
+
+//  Returns the statements adding the new values of an enum, in their positions.
+//  Fails if the type isn't an enum, or values have been removed or reordered,
+//  which enums don't support.
+func addEnumValues(from, to schemaObject) ([]string, bool) {
+
+	old, ok := enumValues(from.statement)
+	if !ok {
+		return nil, false
+	}
+
+	new, ok := enumValues(to.statement)
+	if !ok {
+		return nil, false
+	}
+
+	var statements []string
+	index := 0
+	for position, value := range new {
+
+		if index < len(old) && old[index] == value {
+			index++
+			continue
+		}
+
+		statement := fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s", to.name, value)
+		switch {
+		case position > 0:
+			statement += " AFTER " + new[position-1]
+		case len(new) > 1:
+			statement += " BEFORE " + new[1]
+		}
+		statements = append(statements, statement)
+	}
+
+	//  Every old value must be kept, in it's order
+	if index < len(old) {
+		return nil, false
+	}
+
+	return statements, true
+}
+
+//  Returns the quoted values of an enum type. Example: CREATE TYPE public.status AS ENUM ('todo', 'done')
+func enumValues(statement string) ([]string, bool) {
+
+	start := strings.Index(statement, " AS ENUM (")
+	if start < 0 {
+		return nil, false
+	}
+	start += len(" AS ENUM ")
+
+	end := matchingParenthesis(statement, start)
+	if end < 0 {
+		return nil, false
+	}
+
+	var response []string
+	for _, value := range splitTopLevel(statement[start+1:end], ',') {
+		if value = strings.TrimSpace(value); value != "" {
+			response = append(response, value)
+		}
+	}
+
+	return response, true
+}
+
+//  Returns the statements altering the columns and constraints of a table
+func alterTable(from, to schemaObject) []string {
+
+	var statements []string
+
+	for _, name := range from.names {
+		if _, ok := to.definitions[name]; ok {
+			continue
+		}
+
+		if constraint := strings.TrimPrefix(name, "CONSTRAINT "); constraint != name {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", to.name, constraint))
+		} else {
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", to.name, name))
+		}
+	}
+
+	for _, name := range to.names {
+
+		definition := to.definitions[name]
+		previous, ok := from.definitions[name]
+
+		switch {
+		case !ok && strings.HasPrefix(name, "CONSTRAINT "):
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD %s", to.name, definition))
+		case !ok:
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", to.name, definition))
+		case previous == definition:
+			continue
+		case strings.HasPrefix(name, "CONSTRAINT "):
+			statements = append(statements,
+				fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", to.name, strings.TrimPrefix(name, "CONSTRAINT ")),
+				fmt.Sprintf("ALTER TABLE %s ADD %s", to.name, definition))
+		default:
+			statements = append(statements, alterColumn(to.name, name, previous, definition)...)
+		}
+	}
+
+	return statements
+}
+
+//  Returns the statements changing the definition of a column
+func alterColumn(table, name, from, to string) []string {
+
+	old, new := parseColumn(from), parseColumn(to)
+
+	//  Generated columns can't be altered
+	if strings.Contains(old.dataType, " GENERATED ") || strings.Contains(new.dataType, " GENERATED ") {
+		return []string{
+			fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, name),
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, to),
+		}
+	}
+
+	var statements []string
+	prefix := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", table, name)
+
+	if old.dataType != new.dataType {
+		statements = append(statements, fmt.Sprintf("%s TYPE %s USING %s::%s", prefix, new.dataType, name, strings.SplitN(new.dataType, " COLLATE ", 2)[0]))
+	}
+
+	if old.defaultValue != new.defaultValue {
+		if new.defaultValue == "" {
+			statements = append(statements, prefix+" DROP DEFAULT")
+		} else {
+			statements = append(statements, fmt.Sprintf("%s SET DEFAULT %s", prefix, new.defaultValue))
+		}
+	}
+
+	if old.notNull != new.notNull {
+		if new.notNull {
+			statements = append(statements, prefix+" SET NOT NULL")
+		} else {
+			statements = append(statements, prefix+" DROP NOT NULL")
+		}
+	}
+
+	return statements
+}
+
+//  Definition of a column, in the format of pg_dump.
+//  Example: created_at timestamp with time zone DEFAULT now() NOT NULL
+type columnDefinition struct {
+	dataType     string
+	defaultValue string
+	notNull      bool
+}
+
+func parseColumn(definition string) columnDefinition {
+
+	var response columnDefinition
+
+	_, remainder := splitIdentifier(definition)
+
+	if strings.HasSuffix(remainder, " NOT NULL") {
+		response.notNull = true
+		remainder = strings.TrimSuffix(remainder, " NOT NULL")
+	}
+
+	if index := strings.Index(remainder, " DEFAULT "); index >= 0 {
+		response.defaultValue = strings.TrimSpace(remainder[index+len(" DEFAULT "):])
+		remainder = remainder[:index]
+	}
+
+	response.dataType = strings.TrimSpace(remainder)
+
+	return response
+}
+
+//  Splits a schema-only dump of pg_dump into the objects it creates
+func parseSchema(dump string) []schemaObject {
+
+	var response []schemaObject
+
+	for _, statement := range splitStatements(dump) {
+
+		if ignoredStatement.MatchString(statement) {
+			continue
+		}
+
+		item := schemaObject{kind: "statement", name: statement, statement: statement}
+
+		for _, pattern := range schemaStatements {
+			if match := pattern.expression.FindStringSubmatch(statement); match != nil {
+				item.kind, item.name = pattern.kind, strings.Join(strings.Fields(match[1]), " ")
+				break
+			}
+		}
+
+		switch item.kind {
+		case "function", "procedure":
+			item.name = routineSignature(statement)
+		case "table":
+			item.names, item.definitions = tableDefinitions(statement)
+		}
+
+		response = append(response, item)
+	}
+
+	return response
+}
+
+//  Returns the definitions of columns and constraints, in the parentheses of CREATE TABLE,
+//  along with their names. Constraints are named "CONSTRAINT <name>".
+func tableDefinitions(statement string) ([]string, map[string]string) {
+
+	var names []string
+	definitions := make(map[string]string)
+
+	start := strings.Index(statement, "(")
+	end := matchingParenthesis(statement, start)
+	if start < 0 || end < 0 {
+		return names, definitions
+	}
+
+	for _, definition := range splitTopLevel(statement[start+1:end], ',') {
+
+		definition = strings.Join(strings.Fields(definition), " ")
+		if definition == "" {
+			continue
+		}
+
+		name, remainder := splitIdentifier(definition)
+		if name == "CONSTRAINT" {
+			constraint, _ := splitIdentifier(remainder)
+			name = "CONSTRAINT " + constraint
+		}
+
+		names = append(names, name)
+		definitions[name] = definition
+	}
+
+	return names, definitions
+}
+
+//  Returns the name and argument types of a function or procedure,
+//  without names and defaults of arguments. Example: public.search(text, integer)
+func routineSignature(statement string) string {
+
+	start := strings.Index(statement, "(")
+	end := matchingParenthesis(statement, start)
+	if start < 0 || end < 0 {
+		return statement
+	}
+
+	name := strings.Fields(statement[:start])
+	var arguments []string
+
+	for _, argument := range splitTopLevel(statement[start+1:end], ',') {
+
+		argument = strings.TrimSpace(argument)
+		if index := strings.Index(argument, " DEFAULT "); index >= 0 {
+			argument = argument[:index]
+		}
+
+		//  Arguments may have a mode and a name before their type
+		fields := strings.Fields(argument)
+		if len(fields) > 0 {
+			switch fields[0] {
+			case "IN", "OUT", "INOUT", "VARIADIC":
+				if fields[0] == "OUT" {
+					continue
+				}
+				fields = fields[1:]
+			}
+		}
+		if len(fields) > 1 && !isTypeContinuation(fields[1]) {
+			fields = fields[1:]
+		}
+
+		arguments = append(arguments, strings.Join(fields, " "))
+	}
+
+	return fmt.Sprintf("%s(%s)", name[len(name)-1], strings.Join(arguments, ", "))
+}
+
+//  Whether the word continues the name of a type, like "precision" of "double precision".
+//  Names of arguments are never followed by one of them.
+func isTypeContinuation(word string) bool {
+	switch word {
+	case "precision", "varying", "with", "without", "zone", "time":
+		return true
+	}
+	return strings.HasPrefix(word, "(") || strings.HasPrefix(word, "[")
+}
+
+//  Splits SQL into statements on semicolons, which aren't part of
+//  strings, quoted identifiers, dollar-quoted bodies or comments.
+//  Statements are returned without comments and the trailing semicolon.
+func splitStatements(sql string) []string {
+
+	var response []string
+	var current strings.Builder
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			response = append(response, statement)
+		}
+		current.Reset()
+	}
+
+	for index := 0; index < len(sql); index++ {
+
+		char := sql[index]
+
+		switch {
+		case char == ';':
+			flush()
+			continue
+
+		case strings.HasPrefix(sql[index:], "--"):
+			end := strings.IndexByte(sql[index:], '\n')
+			if end < 0 {
+				end = len(sql) - index
+			}
+			index += end - 1
+			continue
+
+		case strings.HasPrefix(sql[index:], "/*"):
+			end := strings.Index(sql[index+2:], "*/")
+			if end < 0 {
+				end = len(sql) - index - 4
+			}
+			index += end + 3
+			continue
+
+		case char == '\'' || char == '"':
+			end := index + 1
+			for end < len(sql) {
+				if sql[end] == char {
+					if end+1 < len(sql) && sql[end+1] == char {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end >= len(sql) {
+				end = len(sql) - 1
+			}
+			current.WriteString(sql[index : end+1])
+			index = end
+			continue
+
+		case char == '$':
+			if tag := dollarTag.FindString(sql[index:]); tag != "" {
+				end := strings.Index(sql[index+len(tag):], tag)
+				if end < 0 {
+					end = len(sql) - index - 2*len(tag)
+				}
+				current.WriteString(sql[index : index+2*len(tag)+end])
+				index += 2*len(tag) + end - 1
+				continue
+			}
+		}
+
+		current.WriteByte(char)
+	}
+
+	flush()
+
+	return response
+}
+
+//  Opening tag of a dollar-quoted string. Example: $$, $function$
+var dollarTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+//  Splits the text on the separator, outside of parentheses and quotes
+func splitTopLevel(text string, separator byte) []string {
+
+	var response []string
+	depth, start := 0, 0
+	var quote byte
+
+	for index := 0; index < len(text); index++ {
+		char := text[index]
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '(':
+			depth++
+		case char == ')':
+			depth--
+		case char == separator && depth == 0:
+			response = append(response, text[start:index])
+			start = index + 1
+		}
+	}
+
+	return append(response, text[start:])
+}
+
+//  Returns the index of the parenthesis closing the one at the index
+func matchingParenthesis(text string, start int) int {
+
+	if start < 0 {
+		return -1
+	}
+
+	depth := 0
+	var quote byte
+
+	for index := start; index < len(text); index++ {
+		char := text[index]
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '(':
+			depth++
+		case char == ')':
+			depth--
+			if depth == 0 {
+				return index
+			}
+		}
+	}
+
+	return -1
+}
+
+//  Splits the leading, possibly quoted, identifier from the rest of the text
+func splitIdentifier(text string) (string, string) {
+
+	text = strings.TrimSpace(text)
+
+	if strings.HasPrefix(text, `"`) {
+		for index := 1; index < len(text); index++ {
+			if text[index] == '"' {
+				if index+1 < len(text) && text[index+1] == '"' {
+					index++
+					continue
+				}
+				return text[:index+1], strings.TrimSpace(text[index+1:])
+			}
+		}
+		return text, ""
+	}
+
+	if index := strings.IndexByte(text, ' '); index >= 0 {
+		return text[:index], strings.TrimSpace(text[index+1:])
+	}
+
+	return text, ""
+}
+
+//  Splits the text around the first occurrence of the keyword
+func splitAround(text, keyword string) (string, string) {
+
+	parts := strings.SplitN(text, " "+keyword+" ", 2)
+	if len(parts) < 2 {
+		return text, ""
+	}
+
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+//  Qualifies the name with the schema of the table. Example: todos_idx, public.todos -> public.todos_idx
+func qualify(name, table string) string {
+
+	if index := strings.LastIndex(table, "."); index >= 0 && !strings.Contains(name, ".") {
+		return table[:index+1] + name
+	}
+
+	return name
+}
@@ -0,0 +1,166 @@
+package hasura
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const localSchema = `SET statement_timeout = 0;
+SELECT pg_catalog.set_config('search_path', '', false);
+CREATE TYPE public.status AS ENUM (
+    'todo',
+    'done'
+);
+CREATE DOMAIN public.email AS text CHECK ((VALUE ~~ '%@%'::text));
+CREATE FUNCTION public.set_updated_at() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+  NEW.updated_at = now(); -- keep it fresh
+  RETURN NEW;
+END;
+$$;
+CREATE TABLE public.todos (
+    id uuid DEFAULT public.gen_random_uuid() NOT NULL,
+    title text,
+    done boolean DEFAULT false,
+    priority integer,
+    updated_at timestamp with time zone
+);
+CREATE TABLE public.legacy (
+    id integer NOT NULL
+);
+ALTER TABLE ONLY public.todos
+    ADD CONSTRAINT todos_pkey PRIMARY KEY (id);
+CREATE INDEX todos_title_idx ON public.todos USING btree (title);
+`
+
+const remoteSchema = `SET statement_timeout = 0;
+CREATE SCHEMA app;
+CREATE TYPE public.status AS ENUM (
+    'backlog',
+    'todo',
+    'doing',
+    'done'
+);
+CREATE DOMAIN public.email AS text CHECK ((length(VALUE) > 3));
+CREATE FUNCTION public.set_updated_at() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+  NEW.updated_at = clock_timestamp(); -- keep it fresh; always
+  RETURN NEW;
+END;
+$$;
+CREATE FUNCTION app.search(query text, "limit" integer DEFAULT 10) RETURNS SETOF public.todos
+    LANGUAGE sql STABLE
+    AS $_$ SELECT * FROM public.todos WHERE title ILIKE '%' || $1 || '%' LIMIT $2 $_$;
+CREATE TABLE public.todos (
+    id uuid DEFAULT public.gen_random_uuid() NOT NULL,
+    title text NOT NULL,
+    done boolean,
+    "order" integer DEFAULT 0 NOT NULL,
+    updated_at timestamp with time zone,
+    CONSTRAINT title_length CHECK ((length(title) > 0))
+);
+CREATE TABLE app.settings (
+    key text NOT NULL,
+    value text
+);
+ALTER TABLE ONLY public.todos
+    ADD CONSTRAINT todos_pkey PRIMARY KEY (id);
+CREATE TRIGGER set_todos_updated_at BEFORE UPDATE ON public.todos FOR EACH ROW EXECUTE FUNCTION public.set_updated_at();
+COMMENT ON TABLE app.settings IS 'Settings; of the app';
+`
+
+func TestDiffSchema(t *testing.T) {
+
+	diff := DiffSchema(localSchema, remoteSchema)
+
+	if !reflect.DeepEqual(diff.Tables, []string{"app.settings"}) {
+		t.Errorf("Tables = %v, want [app.settings]", diff.Tables)
+	}
+
+	up := strings.Split(strings.TrimSuffix(diff.Up, ";\n"), ";\n\n")
+	want := []string{
+		"CREATE SCHEMA app",
+		"ALTER TYPE public.status ADD VALUE IF NOT EXISTS 'backlog' BEFORE 'todo'",
+		"ALTER TYPE public.status ADD VALUE IF NOT EXISTS 'doing' AFTER 'todo'",
+		"-- Revert manually: CREATE DOMAIN public.email AS text CHECK ((length(VALUE) > 3))",
+		"CREATE OR REPLACE FUNCTION public.set_updated_at() RETURNS trigger",
+		"CREATE FUNCTION app.search(query text, \"limit\" integer DEFAULT 10)",
+		"ALTER TABLE public.todos ALTER COLUMN title SET NOT NULL",
+		"ALTER TABLE public.todos ALTER COLUMN done DROP DEFAULT",
+		"ALTER TABLE public.todos ADD COLUMN \"order\" integer DEFAULT 0 NOT NULL",
+		"ALTER TABLE public.todos ADD CONSTRAINT title_length CHECK ((length(title) > 0))",
+		"CREATE TABLE app.settings (",
+		"CREATE TRIGGER set_todos_updated_at BEFORE UPDATE ON public.todos",
+		"COMMENT ON TABLE app.settings IS 'Settings; of the app'",
+	}
+
+	if len(up) != len(want) {
+		t.Fatalf("Up = %s, want %d statements", diff.Up, len(want))
+	}
+
+	for index := range want {
+		if !strings.HasPrefix(up[index], want[index]) {
+			t.Errorf("statement %d of Up = %q, want it to start with %q", index, up[index], want[index])
+		}
+	}
+
+	//  Reverting drops what's been created, and restores what's been changed
+	for _, want := range []string{
+		"COMMENT ON TABLE app.settings IS NULL",
+		"DROP TRIGGER IF EXISTS set_todos_updated_at ON public.todos",
+		"DROP TABLE IF EXISTS app.settings",
+		"DROP FUNCTION IF EXISTS app.search(text, integer)",
+		"DROP SCHEMA IF EXISTS app",
+		"ALTER TABLE public.todos DROP COLUMN IF EXISTS \"order\"",
+		"ALTER TABLE public.todos DROP CONSTRAINT IF EXISTS title_length",
+		"ALTER TABLE public.todos ALTER COLUMN done SET DEFAULT false",
+	} {
+		if !strings.Contains(diff.Down, want) {
+			t.Errorf("Down = %s, want it to contain %q", diff.Down, want)
+		}
+	}
+
+	//  Types, which columns may depend on, are never dropped.
+	//  Removed enum values and changed domains have to be reverted manually.
+	if strings.Contains(diff.Up, "DROP TYPE") || strings.Contains(diff.Up, "DROP DOMAIN") || strings.Contains(diff.Down, "DROP TYPE") || strings.Contains(diff.Down, "DROP DOMAIN") {
+		t.Errorf("DiffSchema() = %+v, want changed types to be kept", diff)
+	}
+
+	for _, want := range []string{"-- Revert manually: CREATE TYPE public.status AS ENUM (", "-- Revert manually: CREATE DOMAIN public.email AS text CHECK ((VALUE ~~"} {
+		if !strings.Contains(diff.Down, want) {
+			t.Errorf("Down = %s, want it to contain %q", diff.Down, want)
+		}
+	}
+
+	//  Objects, which only exist in the first schema, are left alone
+	for _, name := range []string{"legacy", "priority", "todos_title_idx"} {
+		if strings.Contains(diff.Up, name) || strings.Contains(diff.Down, name) {
+			t.Errorf("DiffSchema() = %+v, want %s to be left alone", diff, name)
+		}
+	}
+
+	if diff := DiffSchema(remoteSchema, remoteSchema); diff.Up != "" || diff.Down != "" {
+		t.Errorf("DiffSchema() of equal schemas = %+v, want no statements", diff)
+	}
+}
+
+func TestRoutineSignature(t *testing.T) {
+
+	tests := map[string]string{
+		`CREATE FUNCTION public.f() RETURNS trigger`:                                                        "public.f()",
+		`CREATE FUNCTION public.f(a integer, b double precision DEFAULT 1.0) RETURNS integer`:               "public.f(integer, double precision)",
+		`CREATE FUNCTION public.f(timestamp with time zone, OUT total numeric(10,2)) RETURNS numeric`:       "public.f(timestamp with time zone)",
+		`CREATE PROCEDURE public.p(INOUT "user" public.users, VARIADIC ids integer[]) LANGUAGE sql AS $$$$`: "public.p(public.users, integer[])",
+	}
+
+	for statement, want := range tests {
+		if got := routineSignature(statement); got != want {
+			t.Errorf("routineSignature(%q) = %q, want %q", statement, got, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package hasura
+
+import "fmt"
+
+//  Table recording the seeds applied to the database.
+//  Hasura's catalog schema is never part of migrations or dumps.
+const SEEDS_TABLE = "hdb_catalog.nhost_seeds"
+
+//  Returns the checksums of applied seeds, by their names
+func (c *Client) AppliedSeeds() (map[string]string, error) {
+
+	log.Debug("Fetching applied seeds")
+
+	response := make(map[string]string)
+
+	if _, err := c.RunSQL(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	name text PRIMARY KEY,
+	checksum text NOT NULL,
+	applied_at timestamptz NOT NULL DEFAULT now()
+);`, SEEDS_TABLE)); err != nil {
+		return response, err
+	}
+
+	result, err := c.RunSQL(fmt.Sprintf("SELECT name, checksum FROM %s;", SEEDS_TABLE))
+	if err != nil {
+		return response, err
+	}
+
+	//  The first row contains column names
+	for index, row := range result.Rows {
+		if index > 0 && len(row) == 2 {
+			response[fmt.Sprint(row[0])] = fmt.Sprint(row[1])
+		}
+	}
+
+	return response, nil
+}
+
+//  Applies the seed, and records it's checksum, in a single transaction.
+//  If the seed fails, neither it's changes, nor the record, are saved.
+func (c *Client) RunSeed(name, checksum, sql string) error {
+
+	log.WithField("seed", name).Debug("Applying seed")
+
+	//  The seed may end without a semicolon, or with a comment
+	_, err := c.RunSQL(fmt.Sprintf(`%s
+;
+INSERT INTO %s (name, checksum) VALUES (%s, %s)
+ON CONFLICT (name) DO UPDATE SET checksum = EXCLUDED.checksum, applied_at = now();`,
+		sql, SEEDS_TABLE, quoteLiteral(name), quoteLiteral(checksum)))
+
+	return err
+}
+
+//  Forgets all applied seeds, so that all of them are applied again
+func (c *Client) ClearSeeds() error {
+
+	log.Debug("Clearing applied seeds")
+
+	_, err := c.RunSQL(fmt.Sprintf("DROP TABLE IF EXISTS %s;", SEEDS_TABLE))
+	return err
+}
@@ -0,0 +1,170 @@
+package hasura
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/nhost/cli/nhost"
+)
+
+//  Result of a run_sql query
+type SQLResult struct {
+
+	//  "TuplesOk" for queries returning rows, otherwise "CommandOk"
+	Type string `json:"result_type"`
+
+	//  Rows returned by the query, the first one containing column names.
+	//  Values are either strings or nil, for NULL.
+	Rows [][]interface{} `json:"result"`
+}
+
+//  Runs the SQL on the app's database through run_sql
+func (c *Client) RunSQL(sql string) (SQLResult, error) {
+	return c.runSQL(nhost.DATABASE, sql)
+}
+
+//  Runs the SQL on the database of the source, in a single transaction
+func (c *Client) runSQL(source, sql string) (SQLResult, error) {
+
+	log.WithField("source", source).Debug("Running SQL")
+
+	var response SQLResult
+
+	reqBody := RequestBody{
+		Type: "run_sql",
+		Args: map[string]string{
+			"source": source,
+			"sql":    sql,
+		},
+	}
+
+	body, err := reqBody.Marshal()
+	if err != nil {
+		return response, err
+	}
+
+	resp, err := c.Request(body, "/v2/query")
+	if err != nil {
+		return response, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return response, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Response
+			Internal struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"internal"`
+		}
+
+		if err := json.Unmarshal(body, &failure); err != nil || failure.Error == "" {
+			return response, errors.New(string(body))
+		}
+
+		//  Postgres errors are more helpful than Hasura's summary of them
+		if failure.Internal.Error.Message != "" {
+			return response, errors.New(failure.Internal.Error.Message)
+		}
+
+		return response, errors.New(failure.Error)
+	}
+
+	err = json.Unmarshal(body, &response)
+	return response, err
+}
+
+//  Drops all objects of the schemas, except those of extensions,
+//  like pgcrypto and citext, which are used by other schemas too.
+//  Schemas other than public are dropped entirely.
+func (c *Client) ResetSchemas(schemas []string) error {
+
+	log.Debug("Resetting schemas: ", schemas)
+
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	_, err := c.RunSQL(resetSQL(schemas))
+	return err
+}
+
+//  Returns the SQL to reset the schemas
+func resetSQL(schemas []string) string {
+
+	var quoted []string
+	for _, item := range schemas {
+		quoted = append(quoted, quoteLiteral(item))
+	}
+
+	return fmt.Sprintf(`DO $$
+DECLARE
+	item record;
+	statement text;
+	schemas text[] := ARRAY[%s];
+BEGIN
+	FOR item IN SELECT nspname FROM pg_namespace WHERE nspname = ANY(schemas) AND nspname <> 'public' LOOP
+		EXECUTE format('DROP SCHEMA %%I CASCADE', item.nspname);
+	END LOOP;
+
+	IF NOT 'public' = ANY(schemas) THEN
+		RETURN;
+	END IF;
+
+	-- Objects may have been dropped already, by cascading earlier drops,
+	-- in which case no statement is found for them
+
+	FOR item IN SELECT c.oid FROM pg_class c
+		WHERE c.relnamespace = 'public'::regnamespace AND c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S')
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.deptype IN ('e', 'i'))
+	LOOP
+		SELECT format('DROP %%s IF EXISTS %%s CASCADE',
+			CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'f' THEN 'FOREIGN TABLE' WHEN 'S' THEN 'SEQUENCE' ELSE 'TABLE' END,
+			c.oid::regclass) INTO statement FROM pg_class c WHERE c.oid = item.oid;
+		IF statement IS NOT NULL THEN
+			EXECUTE statement;
+		END IF;
+	END LOOP;
+
+	FOR item IN SELECT p.oid FROM pg_proc p
+		WHERE p.pronamespace = 'public'::regnamespace
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype IN ('e', 'i'))
+	LOOP
+		SELECT format('DROP %%s IF EXISTS %%s CASCADE',
+			CASE p.prokind WHEN 'p' THEN 'PROCEDURE' WHEN 'a' THEN 'AGGREGATE' ELSE 'FUNCTION' END,
+			p.oid::regprocedure) INTO statement FROM pg_proc p WHERE p.oid = item.oid;
+		IF statement IS NOT NULL THEN
+			EXECUTE statement;
+		END IF;
+	END LOOP;
+
+	FOR item IN SELECT t.oid FROM pg_type t
+		WHERE t.typnamespace = 'public'::regnamespace AND t.typtype IN ('c', 'd', 'e', 'r')
+		AND (t.typrelid = 0 OR (SELECT relkind FROM pg_class WHERE oid = t.typrelid) = 'c')
+		AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_type'::regclass AND d.objid = t.oid AND d.deptype IN ('e', 'i'))
+	LOOP
+		SELECT format('DROP %%s IF EXISTS %%s CASCADE',
+			CASE t.typtype WHEN 'd' THEN 'DOMAIN' ELSE 'TYPE' END,
+			t.oid::regtype) INTO statement FROM pg_type t WHERE t.oid = item.oid;
+		IF statement IS NOT NULL THEN
+			EXECUTE statement;
+		END IF;
+	END LOOP;
+END $$;`, strings.Join(quoted, ", "))
+}
+
+//  Quotes the value as an SQL string literal
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,173 @@
+package nhost
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nhost/cli/util"
+	"github.com/sirupsen/logrus"
+)
+
+//  Value of `branches.copy_from` in config.yaml,
+//  to copy the data of branch which was checked out before
+const PREVIOUS_BRANCH = "previous"
+
+//  Returns the branch whose data should be copied into a branch which has never run,
+//  as configured in config.yaml. Previous branch is the one checked out before, if any.
+func (c *Configuration) ParentBranch(branch, previous string) string {
+
+	parent := c.Branches.CopyFrom
+	if parent == PREVIOUS_BRANCH {
+		parent = previous
+	}
+
+	//  Nothing to copy from
+	if parent == "" || parent == branch || !HasData(parent) {
+		return ""
+	}
+
+	return parent
+}
+
+//  Checks whether the branch has database data
+func HasData(branch string) bool {
+	return util.PathExists(filepath.Join(BranchDir(branch), "db_data"))
+}
+
+//  Replaces data directories of a branch with a copy of another branch's data.
+//  Containers using the data should be stopped before calling it.
+//
+//  Data is copied into a temporary directory first,
+//  so that a failure doesn't destroy the current data.
+func CopyBranchData(run DataRunner, from, to string) error {
+
+	log.WithFields(logrus.Fields{
+		"from": from,
+		"to":   to,
+	}).Debug("Copying branch data")
+
+	dirs := dataDirs(from)
+	if len(dirs) == 0 {
+		return fmt.Errorf("no data found for branch %s", from)
+	}
+
+	script := `target="$1"; source="$2"; shift 2
+rm -rf .restore && mkdir -p .restore
+for dir in "$@"; do cp -a "$source/$dir" .restore/; done
+set -- "$target"
+` + replaceDataScript
+
+	_, err := run(script, append([]string{to, from}, dirs...)...)
+	return err
+}
+
+//  Returns data directories in .nhost of branches
+//  which don't exist in the local git repository anymore.
+//  Data of the current branch is never considered stale.
+//
+//  Data is owned by the users of containers, so it's sized by the runner.
+func StaleData(run DataRunner) ([]BranchData, error) {
+
+	var response []BranchData
+
+	branches, err := LocalBranches()
+	if err != nil {
+		return response, err
+	}
+
+	//  Without any branch, it's not a git repository yet,
+	//  and every directory would be considered stale
+	if len(branches) == 0 {
+		return response, fmt.Errorf("no local git branches found in %s", GIT_DIR)
+	}
+
+	branches = append(branches, GetBranch())
+
+	files, err := ioutil.ReadDir(filepath.Join(util.WORKING_DIR, ".nhost"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return response, nil
+		}
+		return response, err
+	}
+
+	var names []string
+	for _, file := range files {
+
+		//  Skip the files, like nhost.yaml, and internal directories, like .snapshots
+		if !file.IsDir() || strings.HasPrefix(file.Name(), ".") || util.Contains(branches, file.Name()) {
+			continue
+		}
+
+		names = append(names, file.Name())
+	}
+
+	if len(names) == 0 {
+		return response, nil
+	}
+
+	sizes, err := dataSizes(run, names)
+	if err != nil {
+		return response, err
+	}
+
+	for _, name := range names {
+		response = append(response, BranchData{
+			Branch: name,
+			Path:   BranchDir(name),
+			Size:   sizes[name],
+		})
+	}
+
+	//  Largest first
+	sort.SliceStable(response, func(i, j int) bool {
+		return response[i].Size > response[j].Size
+	})
+
+	return response, nil
+}
+
+//  Deletes all data of the branch, including it's ports and logs
+func DeleteBranchData(run DataRunner, branch string) error {
+
+	log.WithField("branch", branch).Debug("Deleting branch data")
+
+	path, err := dataPath(BranchDir(branch))
+	if err != nil {
+		return err
+	}
+
+	_, err = run(`rm -rf "$1"`, path)
+	return err
+}
+
+//  Returns the sizes in bytes of directories in .nhost, by their names
+func dataSizes(run DataRunner, names []string) (map[string]int64, error) {
+
+	output, err := run(`for dir in "$@"; do du -sb "$dir"; done`, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make(map[string]int64)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size of %s: %s", fields[1], fields[0])
+		}
+		response[fields[1]] = size
+	}
+
+	return response, nil
+}
@@ -0,0 +1,132 @@
+package nhost
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nhost/cli/util"
+)
+
+func TestBranchData(t *testing.T) {
+
+	util.WORKING_DIR = t.TempDir()
+
+	for _, branch := range []string{"main", "feature/login"} {
+		data := filepath.Join(BranchDir(branch), "db_data", "PG_VERSION")
+		if err := os.MkdirAll(filepath.Dir(data), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(data, []byte(branch), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		copyFrom string
+		branch   string
+		previous string
+		want     string
+	}{
+		{name: "not configured", branch: "feature/new", previous: "main"},
+		{name: "named branch", copyFrom: "main", branch: "feature/new", previous: "feature/login", want: "main"},
+		{name: "previous branch", copyFrom: PREVIOUS_BRANCH, branch: "feature/new", previous: "feature/login", want: "feature/login"},
+		{name: "no previous branch", copyFrom: PREVIOUS_BRANCH, branch: "feature/new"},
+		{name: "same branch", copyFrom: "main", branch: "main"},
+		{name: "parent without data", copyFrom: "develop", branch: "feature/new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Configuration{Branches: Branches{CopyFrom: tt.copyFrom}}
+			if got := config.ParentBranch(tt.branch, tt.previous); got != tt.want {
+				t.Errorf("ParentBranch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	//  Files of the branch, which aren't data, are kept
+	ports := filepath.Join(BranchDir("main"), "ports.yaml")
+	if err := ioutil.WriteFile(ports, []byte("hasura: 9201"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CopyBranchData(localRunner, "feature/login", "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(BranchDir("main"), "db_data", "PG_VERSION"))
+	if err != nil || string(content) != "feature/login" {
+		t.Errorf("copied data = %q, %v, want feature/login", content, err)
+	}
+
+	if content, err := ioutil.ReadFile(ports); err != nil || string(content) != "hasura: 9201" {
+		t.Errorf("ports.yaml after CopyBranchData() = %q, %v, want it to be kept", content, err)
+	}
+
+	if err := CopyBranchData(localRunner, "develop", "main"); err == nil {
+		t.Error("CopyBranchData() accepted a branch without data")
+	}
+}
+
+func TestStaleData(t *testing.T) {
+
+	util.WORKING_DIR = t.TempDir()
+
+	defer func(dir string) { GIT_DIR = dir }(GIT_DIR)
+	GIT_DIR = filepath.Join(util.WORKING_DIR, ".git")
+
+	files := map[string]string{
+		filepath.Join(GIT_DIR, "HEAD"):                        "ref: refs/heads/checkout\n",
+		filepath.Join(GIT_DIR, "refs", "heads", "main"):       "a1b2c3\n",
+		filepath.Join(GIT_DIR, "refs", "heads", "fix", "bug"): "a1b2c3\n",
+		filepath.Join(GIT_DIR, "packed-refs"):                 "# pack-refs with: peeled\na1b2c3 refs/heads/packed\na1b2c3 refs/tags/v1\n",
+	}
+
+	//  the current branch has data, but no ref yet
+	for _, branch := range []string{"main", "bug", "packed", "checkout", "deleted", ".snapshots"} {
+		files[filepath.Join(BranchDir(branch), "db_data", "PG_VERSION")] = "13"
+	}
+
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//  Data is owned by the user of the postgres container, and can't be read by the CLI,
+	//  only by the runner, which runs as root
+	data := filepath.Join(BranchDir("deleted"), "db_data")
+	if err := os.Chmod(data, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(data, 0700)
+
+	run := func(script string, args ...string) (string, error) {
+		os.Chmod(data, 0700)
+		defer os.Chmod(data, 0)
+		return localRunner(script, args...)
+	}
+
+	stale, err := StaleData(run)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stale) != 1 || stale[0].Branch != "deleted" || stale[0].Size < 2 {
+		t.Fatalf("StaleData() = %+v, want only branch deleted, including it's database", stale)
+	}
+
+	if err := DeleteBranchData(run, stale[0].Branch); err != nil {
+		t.Fatal(err)
+	}
+
+	if util.PathExists(stale[0].Path) || !HasData("main") {
+		t.Error("DeleteBranchData() didn't delete only the data of the branch")
+	}
+}
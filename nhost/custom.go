@@ -0,0 +1,143 @@
+package nhost
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/nhost/cli/util"
+)
+
+//  Checks whether the service is user-defined in config.yaml,
+//  i.e. it's not one of the Nhost services.
+func IsCustom(name string) bool {
+	_, ok := DEFINITIONS[name]
+	return !ok
+}
+
+//  Applies the defaults of a user-defined service
+func (s *Service) wrapCustom(name string) error {
+
+	if s.Image == "" {
+		return fmt.Errorf("no image mentioned for service %s", name)
+	}
+
+	if s.Version == nil {
+		s.Version = "latest"
+	}
+
+	//  Container port is published on a random host port,
+	//  unless a custom one is mentioned
+	if s.Port == 0 && s.TargetPort != 0 {
+		s.Port = util.GetPort(10000, 10999)
+	}
+
+	if s.TargetPort == 0 {
+		s.TargetPort = s.Port
+	}
+
+	return nil
+}
+
+//  Overrides the published ports of a user-defined service
+//  with it's `port`, `target_port` and any additional `ports`.
+func (s *Service) initCustomConfig() error {
+
+	s.Config.ExposedPorts = nat.PortSet{}
+	s.HostConfig.PortBindings = nat.PortMap{}
+
+	specs := s.Ports
+	if s.Port != 0 {
+		specs = append([]string{fmt.Sprintf("%d:%d", s.Port, s.TargetPort)}, specs...)
+	}
+
+	exposed, bindings, err := nat.ParsePortSpecs(specs)
+	if err != nil {
+		return fmt.Errorf("invalid ports of service %s: %v", s.Name, err)
+	}
+
+	for port := range exposed {
+		s.Config.ExposedPorts[port] = struct{}{}
+	}
+
+	//  Like Nhost services, only publish on the loopback interface,
+	//  unless a custom one is mentioned
+	for port, items := range bindings {
+		for index := range items {
+			if items[index].HostIP == "" {
+				items[index].HostIP = "127.0.0.1"
+			}
+		}
+		s.HostConfig.PortBindings[port] = items
+	}
+
+	return nil
+}
+
+//  Initializes environment variables, command and volumes of a user-defined service
+func (s *Service) initCustom(name, port string) error {
+
+	//  Allow the service to reach Nhost services and functions
+	s.Config.Env = append(s.Config.Env, util.MapToStringArray(util.RuntimeVars(port, true))...)
+
+	//  Docker uses the last value of a variable,
+	//  so values of the user override the generated ones
+	for key, value := range s.Environment {
+		s.Config.Env = append(s.Config.Env, fmt.Sprintf("%v=%v", strings.ToUpper(key), value))
+	}
+
+	if len(s.Command) > 0 {
+		s.Config.Cmd = s.Command
+	}
+
+	for _, volume := range s.Volumes {
+
+		source, target, err := volumeBind(name, volume)
+		if err != nil {
+			return err
+		}
+
+		//  create mount point if it doesn't exist
+		if err := os.MkdirAll(source, os.ModePerm); err != nil {
+			return err
+		}
+
+		s.HostConfig.Binds = append(s.HostConfig.Binds, strings.Join([]string{source, target}, ":"))
+	}
+
+	return nil
+}
+
+//  Splits a `source:target[:options]` volume of a user-defined service
+//  into the host source of it's bind mount, and the rest.
+//
+//  Relative sources are resolved against the app root.
+//  Plain names, like `data:/data`, are stored in .nhost directory of the current branch,
+//  so that they are deleted along with the rest of the app data on `nhost purge`.
+func volumeBind(service, volume string) (string, string, error) {
+
+	//  Don't split Windows drive letters, like C:\data
+	var drive string
+	if len(volume) > 2 && volume[1] == ':' && (volume[2] == '\\' || volume[2] == '/') {
+		drive, volume = volume[:2], volume[2:]
+	}
+
+	payload := strings.SplitN(volume, ":", 2)
+	if len(payload) < 2 || payload[0] == "" || payload[1] == "" {
+		return "", "", fmt.Errorf("invalid volume %q of service %s, expected source:target", drive+volume, service)
+	}
+	payload[0] = drive + payload[0]
+
+	source := payload[0]
+	switch {
+	case filepath.IsAbs(source):
+	case strings.HasPrefix(source, ".") || strings.ContainsAny(source, `/\`):
+		source = filepath.Join(util.WORKING_DIR, source)
+	default:
+		source = filepath.Join(DOT_NHOST, service, source)
+	}
+
+	return source, payload[1], nil
+}
@@ -0,0 +1,118 @@
+package nhost
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/nhost/cli/util"
+)
+
+func TestVolumeBind(t *testing.T) {
+
+	util.WORKING_DIR = "/app"
+	DOT_NHOST = "/app/.nhost/main"
+
+	tests := []struct {
+		volume string
+		source string
+		target string
+		err    bool
+	}{
+		{volume: "/srv/redis:/data", source: "/srv/redis", target: "/data"},
+		{volume: "./redis:/data:ro", source: filepath.Join("/app", "redis"), target: "/data:ro"},
+		{volume: "data:/data", source: filepath.Join("/app/.nhost/main", "redis", "data"), target: "/data"},
+		{volume: "/data", err: true},
+		{volume: ":/data", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.volume, func(t *testing.T) {
+
+			source, target, err := volumeBind("redis", tt.volume)
+			if (err != nil) != tt.err {
+				t.Fatalf("volumeBind() error = %v, want error %v", err, tt.err)
+			}
+
+			if source != tt.source || target != tt.target {
+				t.Errorf("volumeBind() = %v, %v, want %v, %v", source, target, tt.source, tt.target)
+			}
+		})
+	}
+}
+
+func TestInitCustomConfig(t *testing.T) {
+
+	service := &Service{
+		Name:       "shop_redis",
+		Image:      "redis",
+		Port:       10001,
+		TargetPort: 6379,
+		Ports:      []string{"0.0.0.0:8001:8001"},
+	}
+
+	if err := service.wrapCustom("redis"); err != nil {
+		t.Fatal(err)
+	}
+
+	service.InitConfig()
+	if err := service.initCustomConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if service.Version != "latest" {
+		t.Errorf("Version = %v, want latest", service.Version)
+	}
+
+	want := map[nat.Port]nat.PortBinding{
+		"6379/tcp": {HostIP: "127.0.0.1", HostPort: "10001"},
+		"8001/tcp": {HostIP: "0.0.0.0", HostPort: "8001"},
+	}
+
+	if len(service.HostConfig.PortBindings) != len(want) {
+		t.Fatalf("PortBindings = %v, want %v", service.HostConfig.PortBindings, want)
+	}
+
+	for port, binding := range want {
+		if _, ok := service.Config.ExposedPorts[port]; !ok {
+			t.Errorf("port %v is not exposed", port)
+		}
+		if got := service.HostConfig.PortBindings[port]; len(got) != 1 || got[0] != binding {
+			t.Errorf("PortBindings[%v] = %v, want %v", port, got, binding)
+		}
+	}
+
+	if err := (&Service{}).wrapCustom("worker"); err == nil {
+		t.Error("wrapCustom() accepted a service without image")
+	}
+}
+
+func TestInitCustom(t *testing.T) {
+
+	service := &Service{Environment: map[string]interface{}{"nhost_backend_url": "http://shop:1337"}}
+	service.InitConfig()
+
+	if err := service.initCustom("worker", "1337"); err != nil {
+		t.Fatal(err)
+	}
+
+	//  Docker uses the last value of every variable
+	var backend, secret string
+	for _, item := range service.Config.Env {
+		switch {
+		case strings.HasPrefix(item, "NHOST_BACKEND_URL="):
+			backend = item
+		case strings.HasPrefix(item, "NHOST_ADMIN_SECRET="):
+			secret = item
+		}
+	}
+
+	if backend != "NHOST_BACKEND_URL=http://shop:1337" {
+		t.Errorf("initCustom() env = %v, want the value of the user", backend)
+	}
+
+	if secret == "" {
+		t.Error("initCustom() didn't set generated variables")
+	}
+}
@@ -0,0 +1,62 @@
+package nhost
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+
+	//	Detached environment status values
+	DAEMON_STARTING = "starting"
+	DAEMON_ACTIVE   = "active"
+)
+
+//  Loads the state saved by a detached development environment
+func LoadDaemon() (*Daemon, error) {
+
+	log.Debug("Fetching detached environment state")
+
+	var response Daemon
+
+	data, err := ioutil.ReadFile(DAEMON_PATH)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+//  Saves the state of a detached development environment to .nhost/daemon.yaml
+func (d *Daemon) Save() error {
+
+	log.WithField("status", d.Status).Debug("Saving detached environment state")
+
+	data, err := yaml.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(DAEMON_PATH), os.ModePerm); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(DAEMON_PATH, data, 0644)
+}
+
+//  Removes the saved state of a detached development environment
+func RemoveDaemon() error {
+
+	if !pathExists(DAEMON_PATH) {
+		return nil
+	}
+
+	return os.Remove(DAEMON_PATH)
+}
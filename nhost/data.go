@@ -0,0 +1,141 @@
+package nhost
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/nhost/cli/util"
+	"github.com/sirupsen/logrus"
+)
+
+//  Directories of a branch, containing the data of it's services.
+//  Other files of the branch, like ports.yaml, aren't snapshotted or copied.
+var DATA_DIRS = []string{"db_data", "minio"}
+
+//  Runs a shell script in the .nhost directory, with arguments as $1, $2...
+//  Returns what the script printed to stdout.
+//
+//  Data of services is owned by the users of their containers,
+//  like postgres, with permissions which don't let the CLI read it,
+//  so it can only be archived or copied as root.
+type DataRunner func(script string, args ...string) (string, error)
+
+//  Replaces the data directories of branch $1 with the ones in .nhost/.restore,
+//  which has been filled before. Other files of the branch are kept.
+var replaceDataScript = `mkdir -p "$1"
+for dir in ` + strings.Join(DATA_DIRS, " ") + `; do
+	rm -rf "$1/$dir"
+	if [ -e ".restore/$dir" ]; then mv ".restore/$dir" "$1/"; fi
+done
+rm -rf .restore`
+
+//  Returns a runner executing scripts as root in a temporary container
+//  of the image, with .nhost mounted as it's working directory.
+//  Ownership and permissions of the data are preserved.
+func DataContainer(docker *client.Client, ctx context.Context, image string) DataRunner {
+	return func(script string, args ...string) (string, error) {
+
+		if _, _, err := docker.ImageInspectWithRaw(ctx, image); err != nil {
+
+			log.WithField("image", image).Debug("Pulling")
+			reader, err := docker.ImagePull(ctx, image, types.ImagePullOptions{})
+			if err != nil {
+				return "", err
+			}
+			io.Copy(ioutil.Discard, reader)
+			reader.Close()
+		}
+
+		root := filepath.Join(util.WORKING_DIR, ".nhost")
+
+		response, err := docker.ContainerCreate(ctx, &container.Config{
+			Image:      image,
+			User:       "root",
+			Entrypoint: []string{"sh", "-c"},
+			Cmd:        append([]string{"set -e\n" + script, "sh"}, args...),
+			WorkingDir: "/nhost",
+		}, &container.HostConfig{
+			Binds: []string{root + ":/nhost:Z"},
+		}, nil, nil, "")
+		if err != nil {
+			return "", err
+		}
+		defer docker.ContainerRemove(context.Background(), response.ID, types.ContainerRemoveOptions{Force: true})
+
+		log.WithFields(logrus.Fields{
+			"type":      "container",
+			"container": response.ID,
+		}).Debug("Running data script")
+
+		if err := docker.ContainerStart(ctx, response.ID, types.ContainerStartOptions{}); err != nil {
+			return "", err
+		}
+
+		statusCh, errCh := docker.ContainerWait(ctx, response.ID, container.WaitConditionNotRunning)
+		select {
+		case err := <-errCh:
+			return "", err
+		case result := <-statusCh:
+
+			var stdout, stderr bytes.Buffer
+			logs, err := docker.ContainerLogs(ctx, response.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+			if err == nil {
+				stdcopy.StdCopy(&stdout, &stderr, logs)
+				logs.Close()
+			}
+
+			if result.StatusCode == 0 {
+				return stdout.String(), err
+			}
+
+			//  Return the output of the failed script
+			return "", fmt.Errorf("exit code %d: %s", result.StatusCode, strings.TrimSpace(stdout.String()+stderr.String()))
+		}
+	}
+}
+
+//  Returns the data directories, which exist for the branch
+func dataDirs(branch string) []string {
+
+	var response []string
+	for _, dir := range DATA_DIRS {
+		if util.PathExists(filepath.Join(BranchDir(branch), dir)) {
+			response = append(response, dir)
+		}
+	}
+
+	return response
+}
+
+//  Returns the path relative to .nhost, as used by data scripts
+func dataPath(path string) (string, error) {
+
+	relative, err := filepath.Rel(filepath.Join(util.WORKING_DIR, ".nhost"), path)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return "", errors.New("path outside of .nhost: " + path)
+	}
+
+	return filepath.ToSlash(relative), nil
+}
+
+//  Returns the owner of files created by the CLI, for files created by data scripts.
+//  Empty on Windows, where ownership doesn't apply.
+func dataOwner() string {
+
+	if os.Getuid() < 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+}
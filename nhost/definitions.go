@@ -0,0 +1,447 @@
+package nhost
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/nhost/cli/util"
+)
+
+type (
+
+	//  Declarative definition of an Nhost service,
+	//  consumed by both `Configuration.Wrap` and `Configuration.Init`.
+	//
+	//  Env, Cmd, Binds and mount sources are Go templates,
+	//  rendered against `definitionData`.
+	Definition struct {
+		Image   string
+		Version string
+
+		//  range to pick a random port from, unless a custom one is mentioned
+		Ports [2]int
+
+		//  default health check, unless a custom one is mentioned
+		HealthCheck *HealthCheck
+
+		//  default values of service environment in config.yaml
+		Defaults map[string]interface{}
+
+		Env []string
+
+		//  append variables from .env.development
+		Dotenv bool
+
+		//  append NHOST_* and other runtime variables
+		Runtime bool
+
+		//  root key in config.yaml, whose values are appended as PREFIX_KEY variables.
+		//  Example: auth -> AUTH_*
+		Section string
+
+		Entrypoint  []string
+		Cmd         []string
+		Binds       []string
+		Mounts      []mount.Mount
+		Directories []string
+
+		//  services which must be healthy before this one starts
+		DependsOn []string
+
+		//  dev proxy routes
+		Routes []Route
+
+		//  any configuration which can't be declared above
+		Configure func(config *Configuration, service *Service) error
+	}
+
+	//  Data available to the templates of a service definition
+	definitionData struct {
+		Self      *Service
+		Config    *Configuration
+		Port      string
+		DotNhost  string
+		EmailsDir string
+	}
+)
+
+var DEFINITIONS = map[string]*Definition{
+	"postgres": {
+		Image:       "docker.io/nhost/postgres",
+		Version:     "12-v0.0.6",
+		Ports:       [2]int{5000, 5999},
+		HealthCheck: &HealthCheck{SQL: "SELECT 1"},
+		Defaults: map[string]interface{}{
+			"postgres_user":     DB_USER,
+			"postgres_password": DB_PASSWORD,
+		},
+		Cmd:   []string{"-p", "{{ .Self.Port }}"},
+		Binds: []string{`{{ join .DotNhost "db_data" }}:/var/lib/postgresql/data:Z`},
+	},
+	"hasura": {
+		Image:       "docker.io/hasura/graphql-engine",
+		Version:     "v2.2.0",
+		Ports:       [2]int{9200, 9300},
+		HealthCheck: &HealthCheck{HTTP: "/healthz"},
+		Env: []string{
+			"HASURA_GRAPHQL_SERVER_PORT={{ .Self.Port }}",
+			`HASURA_GRAPHQL_DATABASE_URL={{ (service "postgres").Address }}`,
+			"HASURA_GRAPHQL_ENABLE_CONSOLE=false",
+			"HASURA_GRAPHQL_ENABLED_LOG_TYPES=startup, http-log, webhook-log, websocket-log, query-log",
+			"HASURA_GRAPHQL_MIGRATIONS_SERVER_TIMEOUT=20",
+			"HASURA_GRAPHQL_NO_OF_RETRIES=20",
+			"HASURA_GRAPHQL_UNAUTHORIZED_ROLE=public",
+		},
+		Dotenv:    true,
+		Runtime:   true,
+		DependsOn: []string{"postgres"},
+		Routes: []Route{
+			{Name: "GraphQL", Source: "/v1/graphql", Destination: "/v1/graphql"},
+			{Name: "Query", Source: "/v2/query", Destination: "/v2/query", Hidden: true},
+			{Name: "Metadata", Source: "/v1/metadata", Destination: "/v1/metadata", Hidden: true},
+			{Name: "Config", Source: "/v1/config", Destination: "/v1/config", Hidden: true},
+		},
+	},
+	"auth": {
+		Image:       "docker.io/nhost/hasura-auth",
+		Version:     "0.6.3",
+		Ports:       [2]int{9000, 9100},
+		HealthCheck: &HealthCheck{HTTP: "/healthz"},
+		Env: []string{
+			`HASURA_GRAPHQL_DATABASE_URL={{ (service "postgres").Address }}`,
+			`HASURA_GRAPHQL_GRAPHQL_URL=http://{{ (service "hasura").Name }}:{{ (service "hasura").Port }}/v1/graphql`,
+			"AUTH_PORT={{ .Self.Port }}",
+			"AUTH_SERVER_URL=http://localhost:{{ .Port }}/v1/auth",
+			`AUTH_CLIENT_URL={{ index .Config.Auth "client_url" }}`,
+			"AUTH_LOG_LEVEL=info",
+			"AUTH_HOST=0.0.0.0",
+		},
+		Runtime: true,
+		Section: "auth",
+		Mounts: []mount.Mount{
+			{Type: mount.TypeBind, Source: `{{ join .DotNhost "custom" }}`, Target: "/app/custom"},
+			{Type: mount.TypeBind, Source: "{{ .EmailsDir }}", Target: "/app/email-templates"},
+		},
+		Directories: []string{`{{ join .DotNhost "custom" "keys" }}`},
+		DependsOn:   []string{"postgres", "hasura"},
+		Routes:      []Route{{Name: "Authentication", Source: "/", Destination: "/v1/auth/"}},
+	},
+	"storage": {
+		Image:       "docker.io/nhost/hasura-storage",
+		Version:     "0.2.2",
+		Ports:       [2]int{8501, 8799},
+		HealthCheck: &HealthCheck{HTTP: "/healthz"},
+		Env: []string{
+			"BIND=:{{ .Self.Port }}",
+			"PUBLIC_URL=http://localhost:{{ .Self.Port }}",
+			"HASURA_METADATA=1",
+			`HASURA_ENDPOINT=http://{{ (service "hasura").Name }}:{{ (service "hasura").Port }}/v1`,
+			"HASURA_GRAPHQL_ADMIN_SECRET={{ secret }}",
+			`S3_ACCESS_KEY={{ (service "minio").Environment.minio_root_user }}`,
+			`S3_SECRET_KEY={{ (service "minio").Environment.minio_root_password }}`,
+			"S3_BUCKET=nhost",
+			`S3_ENDPOINT={{ with service "minio" }}{{ if .NoContainer }}{{ .Address }}{{ else }}http://{{ .Name }}:{{ .Port }}{{ end }}{{ end }}`,
+			"POSTGRES_MIGRATIONS=1",
+			`POSTGRES_MIGRATIONS_SOURCE={{ (service "postgres").Address }}`,
+		},
+		Runtime:   true,
+		Section:   "storage",
+		Cmd:       []string{"serve"},
+		DependsOn: []string{"postgres", "hasura", "minio"},
+		Routes:    []Route{{Name: "Storage", Source: "/v1/", Destination: "/v1/storage/"}},
+	},
+	"minio": {
+		Image:       "minio/minio",
+		Version:     "RELEASE.2021-09-24T00-24-24Z",
+		Ports:       [2]int{8200, 8500},
+		HealthCheck: &HealthCheck{HTTP: "/minio/health/live"},
+		Defaults: map[string]interface{}{
+			"minio_root_user":     MINIO_USER,
+			"minio_root_password": MINIO_PASSWORD,
+		},
+		Entrypoint: []string{"sh"},
+		Cmd:        []string{"-c", "mkdir -p /data/nhost && /opt/bin/minio server --address :{{ .Self.Port }} /data"},
+		Mounts: []mount.Mount{
+			{Type: mount.TypeBind, Source: `{{ join .DotNhost "minio" "data" }}`, Target: "/data"},
+			{Type: mount.TypeBind, Source: `{{ join .DotNhost "minio" "config" }}`, Target: "/.minio"},
+		},
+	},
+	"mailhog": {
+		Image:       "docker.io/mailhog/mailhog",
+		Version:     "v1.0.1",
+		Ports:       [2]int{8800, 8900},
+		HealthCheck: &HealthCheck{HTTP: "/"},
+		Env: []string{
+			"MH_UI_BIND_ADDR=0.0.0.0:{{ .Self.Port }}",
+			"MH_API_BIND_ADDR=0.0.0.0:{{ .Self.Port }}",
+		},
+		Configure: configureMailhog,
+	},
+}
+
+//  Returns the names of all Nhost services, in alphabetical order
+func definitionNames() []string {
+
+	var response []string
+	for name := range DEFINITIONS {
+		response = append(response, name)
+	}
+
+	sort.Strings(response)
+	return response
+}
+
+//  Applies the defaults of the definition to a service parsed from config.yaml
+func (d *Definition) wrap(s *Service) {
+
+	if s.Port == 0 {
+		s.Port = util.GetPort(d.Ports[0], d.Ports[1])
+	}
+
+	if s.Version == nil {
+		s.Version = d.Version
+	}
+
+	if s.Image == "" {
+		s.Image = d.Image
+	}
+
+	//  `health_endpoint` takes precedence over the default check
+	if s.HealthCheck == nil && s.HealthEndpoint == "" && d.HealthCheck != nil {
+		check := *d.HealthCheck
+		s.HealthCheck = &check
+	}
+}
+
+//  Adds the default values of service environment, if missing in config.yaml.
+//
+//	Backward compatibility for CLI < v0.6,
+//	where credentials are not available in config.yaml.
+func (d *Definition) setDefaults(s *Service) {
+
+	if len(d.Defaults) == 0 {
+		return
+	}
+
+	if s.Environment == nil {
+		s.Environment = make(map[string]interface{})
+	}
+
+	for key, value := range d.Defaults {
+
+		var exists bool
+		for item := range s.Environment {
+			if strings.EqualFold(item, key) {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			s.Environment[key] = value
+		}
+	}
+
+	//	Update the service address, since it may depend on these values
+	s.Address = GetAddress(s)
+}
+
+//  Initializes environment variables, command and mount points of the service container
+func (d *Definition) configure(config *Configuration, s *Service, port string, dotenv []string) error {
+
+	data := definitionData{
+		Self:      s,
+		Config:    config,
+		Port:      port,
+		DotNhost:  DOT_NHOST,
+		EmailsDir: EMAILS_DIR,
+	}
+
+	render := func(items []string) ([]string, error) {
+		var response []string
+		for _, item := range items {
+			value, err := renderTemplate(config, item, data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure %s: %v", s.Name, err)
+			}
+			response = append(response, value)
+		}
+		return response, nil
+	}
+
+	env, err := render(d.Env)
+	if err != nil {
+		return err
+	}
+
+	if d.Dotenv {
+		env = append(env, dotenv...)
+	}
+
+	if d.Runtime {
+		env = append(env, util.MapToStringArray(util.RuntimeVars(port, true))...)
+	}
+
+	switch d.Section {
+	case "auth":
+		env = append(env, ParseEnvVarsFromConfig(config.Auth, "AUTH")...)
+	case "storage":
+		env = append(env, ParseEnvVarsFromConfig(config.Storage, "STORAGE")...)
+	}
+
+	//  append service specific environment variables
+	for key, value := range s.Environment {
+		env = append(env, fmt.Sprintf("%v=%v", strings.ToUpper(key), value))
+	}
+
+	s.Config.Env = env
+
+	if len(d.Entrypoint) > 0 {
+		s.Config.Entrypoint = d.Entrypoint
+	}
+
+	if len(d.Cmd) > 0 {
+		if s.Config.Cmd, err = render(d.Cmd); err != nil {
+			return err
+		}
+	}
+
+	//  create mount points and directories if they don't exist
+	directories, err := render(d.Directories)
+	if err != nil {
+		return err
+	}
+
+	if len(d.Binds) > 0 {
+		if s.HostConfig.Binds, err = render(d.Binds); err != nil {
+			return err
+		}
+		for _, bind := range s.HostConfig.Binds {
+
+			//  Don't split Windows drive letters, like C:\data
+			drive := filepath.VolumeName(bind)
+			directories = append(directories, drive+strings.Split(strings.TrimPrefix(bind, drive), ":")[0])
+		}
+	}
+
+	if len(d.Mounts) > 0 {
+		s.HostConfig.Mounts = nil
+		for _, item := range d.Mounts {
+			source, err := render([]string{item.Source})
+			if err != nil {
+				return err
+			}
+			item.Source = source[0]
+			s.HostConfig.Mounts = append(s.HostConfig.Mounts, item)
+			directories = append(directories, item.Source)
+		}
+	}
+
+	for _, item := range directories {
+		if err := os.MkdirAll(item, os.ModePerm); err != nil {
+			status.Errorln(fmt.Sprintf("Failed to create %s directory", item))
+			return err
+		}
+	}
+
+	if d.Configure != nil {
+		return d.Configure(config, s)
+	}
+
+	return nil
+}
+
+//  Renders a template of service definition
+func renderTemplate(config *Configuration, text string, data definitionData) (string, error) {
+
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"service": func(name string) (*Service, error) {
+			if config.Services[name] == nil {
+				return nil, fmt.Errorf("no such service: %s", name)
+			}
+			return config.Services[name], nil
+		},
+		"join":   filepath.Join,
+		"secret": func() string { return util.ADMIN_SECRET },
+	}).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+//  Returns the dev proxy routes of a service,
+//  including the ones mentioned in config.yaml
+func ProxyRoutes(name string, s *Service) []Route {
+
+	var response []Route
+
+	if definition, ok := DEFINITIONS[name]; ok {
+		response = append(response, definition.Routes...)
+	}
+
+	for _, route := range s.Routes {
+		if route.Name == "" {
+			route.Name = strings.Title(name)
+		}
+		response = append(response, route)
+	}
+
+	return response
+}
+
+//  Mailhog serves SMTP on the port mentioned in auth configuration,
+//  unless a custom SMTP server is mentioned.
+func configureMailhog(config *Configuration, s *Service) error {
+
+	var smtpPort int
+	for _, item := range ParseEnvVarsFromConfig(config.Auth, "AUTH") {
+		payload := strings.Split(item, "=")
+		if payload[0] == "AUTH_SMTP_PORT" {
+			smtpPort, _ = strconv.Atoi(payload[1])
+		}
+
+		//	If the SMTP server address inside config.yaml
+		//	doesn't match the container name,
+		//	i.e. it is a custom server address,
+		//	then don't launch the mailhog container
+		if payload[0] == "AUTH_SMTP_HOST" && !IsContainerName(payload[1], "mailhog") {
+			log.WithField("service", "mailhog").Debug("Disabling container launch")
+			s.NoContainer = true
+		}
+	}
+
+	//	If the SMTP port is busy, abort
+	if !util.PortAvailable(strconv.Itoa(smtpPort)) {
+		log.WithField("component", "smtp").Errorf("Port %v not available", smtpPort)
+		log.WithField("component", "smtp").Info("Change your SMTP port in ./nhost/config.yaml")
+		return fmt.Errorf("SMTP port %v not available", smtpPort)
+	}
+
+	s.Config.Env = append(s.Config.Env, fmt.Sprintf("MH_SMTP_BIND_ADDR=0.0.0.0:%v", smtpPort))
+
+	s.HostConfig.PortBindings = map[nat.Port][]nat.PortBinding{
+		nat.Port(strconv.Itoa(smtpPort)): {{HostIP: "127.0.0.1", HostPort: strconv.Itoa(smtpPort)}},
+		nat.Port(strconv.Itoa(s.Port)):   {{HostIP: "127.0.0.1", HostPort: strconv.Itoa(s.Port)}},
+	}
+	s.Config.ExposedPorts = nat.PortSet{
+		nat.Port(strconv.Itoa(smtpPort)): struct{}{},
+		nat.Port(strconv.Itoa(s.Port)):   struct{}{},
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package nhost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//  Returns the services which must be healthy before the given one starts.
+//  Dependencies on services which are not part of the configuration are skipped.
+func (c *Configuration) Dependencies(name string) []string {
+
+	var response []string
+
+	var declared []string
+	if definition, ok := DEFINITIONS[name]; ok {
+		declared = append(declared, definition.DependsOn...)
+	}
+	if c.Services[name] != nil {
+		declared = append(declared, c.Services[name].DependsOn...)
+	}
+
+	for _, item := range declared {
+		if _, ok := c.Services[item]; ok && item != name {
+			response = append(response, item)
+		}
+	}
+
+	return response
+}
+
+//  Validates `depends_on` of all services,
+//  and returns an error if they refer unknown services or form a cycle.
+func (c *Configuration) ValidateDependencies() error {
+
+	var names []string
+	for name, service := range c.Services {
+		names = append(names, name)
+
+		for _, item := range service.DependsOn {
+			if _, ok := c.Services[item]; !ok {
+				return fmt.Errorf("service %s depends on unknown service %s", name, item)
+			}
+		}
+	}
+
+	//  sorted, so that the same cycle is always reported the same way
+	sort.Strings(names)
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[string]int)
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("circular dependency between services: %s", strings.Join(append(path, name), " -> "))
+		}
+
+		state[name] = visiting
+		for _, item := range c.Dependencies(name) {
+			if err := visit(item, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package nhost
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+
+	config := Configuration{
+		Services: map[string]*Service{
+			"postgres": {},
+			"hasura":   {},
+			"storage":  {},
+			"worker":   {DependsOn: []string{"hasura"}},
+		},
+	}
+
+	tests := map[string][]string{
+		"postgres": nil,
+		"hasura":   {"postgres"},
+
+		//  minio isn't part of the configuration
+		"storage": {"postgres", "hasura"},
+		"worker":  {"hasura"},
+	}
+
+	for name, want := range tests {
+		if got := config.Dependencies(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("Dependencies(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	if err := config.ValidateDependencies(); err != nil {
+		t.Errorf("ValidateDependencies() error = %v", err)
+	}
+
+	config.Services["postgres"].DependsOn = []string{"worker"}
+	if err := config.ValidateDependencies(); err == nil {
+		t.Error("ValidateDependencies() accepted a circular dependency")
+	}
+
+	config.Services["postgres"].DependsOn = []string{"redis"}
+	if err := config.ValidateDependencies(); err == nil {
+		t.Error("ValidateDependencies() accepted an unknown dependency")
+	}
+}
@@ -0,0 +1,160 @@
+package nhost
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/sirupsen/logrus"
+)
+
+//  Returns a stable hash of the container configuration generated for the service.
+//  Environment variables are sorted, since they are generated from maps in random order.
+func (s *Service) Hash() string {
+
+	config := *s.Config
+
+	config.Env = append([]string{}, s.Config.Env...)
+	sort.Strings(config.Env)
+
+	//  Exclude the hash itself
+	config.Labels = make(map[string]string)
+	for key, value := range s.Config.Labels {
+		if key != LABEL_HASH {
+			config.Labels[key] = value
+		}
+	}
+
+	data, _ := json.Marshal(struct {
+		Config     container.Config
+		HostConfig *container.HostConfig
+	}{config, s.HostConfig})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+//  Fetches container's configuration, mount points and host configuration,
+//  and validates them against configuration initialized by Nhost for it's respective service.
+//  Returns a human readable list of differences, which is empty if the container is up to date.
+func (s *Service) Inspect(client *client.Client, ctx context.Context) ([]string, error) {
+
+	log.WithFields(logrus.Fields{
+		"type":      "container",
+		"component": s.Name,
+	}).Debug("Inspecting")
+
+	var response []string
+
+	data, err := client.ContainerInspect(ctx, s.ID)
+	if err != nil {
+		return response, err
+	}
+
+	if data.Config.Labels[LABEL_HASH] == s.Config.Labels[LABEL_HASH] {
+		return response, nil
+	}
+
+	if data.Config.Labels[LABEL_HASH] == "" {
+		response = append(response, "created by an older CLI")
+	}
+
+	if data.Config.Image != s.Config.Image {
+		response = append(response, fmt.Sprintf("image %s -> %s", data.Config.Image, s.Config.Image))
+	}
+
+	//  Variables baked into the image are not part of the generated configuration
+	var defaults []string
+	if image, _, err := client.ImageInspectWithRaw(ctx, data.Image); err == nil && image.Config != nil {
+		defaults = image.Config.Env
+	}
+
+	response = append(response, diffEnv(data.Config.Env, s.Config.Env, defaults)...)
+
+	//  Docker reports the image's command and entrypoint, if none were supplied
+	if s.Config.Cmd != nil && strings.Join(data.Config.Cmd, " ") != strings.Join(s.Config.Cmd, " ") {
+		response = append(response, "command")
+	}
+
+	if s.Config.Entrypoint != nil && strings.Join(data.Config.Entrypoint, " ") != strings.Join(s.Config.Entrypoint, " ") {
+		response = append(response, "entrypoint")
+	}
+
+	if fmt.Sprint(data.HostConfig.PortBindings) != fmt.Sprint(s.HostConfig.PortBindings) {
+		response = append(response, "ports")
+	}
+
+	if mounts(data.HostConfig) != mounts(s.HostConfig) {
+		response = append(response, "mounts")
+	}
+
+	//  Something we don't summarize has changed, like labels or restart policy
+	if len(response) == 0 {
+		response = append(response, "configuration")
+	}
+
+	return response, nil
+}
+
+//  Returns bind and mount points of the container as a comparable string
+func mounts(config *container.HostConfig) string {
+
+	response := append([]string{}, config.Binds...)
+	for _, item := range config.Mounts {
+		response = append(response, fmt.Sprintf("%s:%s", item.Source, item.Target))
+	}
+
+	return strings.Join(response, ",")
+}
+
+//  Compares container environment variables, and returns the names of the changed ones.
+//  Values are not returned, since they may contain secrets.
+func diffEnv(current, expected, defaults []string) []string {
+
+	var response []string
+
+	parse := func(payload []string) map[string]string {
+		result := make(map[string]string)
+		for _, item := range payload {
+			pair := strings.SplitN(item, "=", 2)
+			if len(pair) == 2 {
+				result[pair[0]] = pair[1]
+			} else {
+				result[pair[0]] = ""
+			}
+		}
+		return result
+	}
+
+	old, new, image := parse(current), parse(expected), parse(defaults)
+
+	for key, value := range new {
+		if previous, ok := old[key]; !ok {
+			response = append(response, "+"+key)
+		} else if previous != value {
+			response = append(response, "~"+key)
+		}
+	}
+
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			if _, ok := image[key]; !ok {
+				response = append(response, "-"+key)
+			}
+		}
+	}
+
+	sort.Strings(response)
+
+	if len(response) > 0 {
+		return []string{"env " + strings.Join(response, " ")}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package nhost
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestHash(t *testing.T) {
+
+	service := func(env ...string) *Service {
+		return &Service{
+			Config: &container.Config{
+				Image:  "docker.io/hasura/graphql-engine:v2.2.0",
+				Env:    env,
+				Labels: map[string]string{LABEL_SERVICE: "hasura"},
+			},
+			HostConfig: &container.HostConfig{},
+		}
+	}
+
+	first := service("A=1", "B=2")
+	second := service("B=2", "A=1")
+
+	if first.Hash() != second.Hash() {
+		t.Error("expected hash to be independent of environment variable order")
+	}
+
+	second.Config.Labels[LABEL_HASH] = second.Hash()
+	if first.Hash() != second.Hash() {
+		t.Error("expected hash to ignore the hash label")
+	}
+
+	if first.Hash() == service("A=1", "B=3").Hash() {
+		t.Error("expected hash to change with environment variables")
+	}
+}
+
+func TestDiffEnv(t *testing.T) {
+
+	got := diffEnv(
+		[]string{"PATH=/bin", "A=1", "B=2", "C=3"},
+		[]string{"A=1", "B=20", "D=4"},
+		[]string{"PATH=/bin"},
+	)
+
+	want := []string{"env +D -C ~B"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffEnv() = %v, want %v", got, want)
+	}
+
+	if got := diffEnv([]string{"A=1"}, []string{"A=1"}, nil); got != nil {
+		t.Errorf("diffEnv() = %v, want nil", got)
+	}
+}
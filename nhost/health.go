@@ -0,0 +1,205 @@
+package nhost
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+
+	//  defaults for health check blocks of config.yaml,
+	//  which add up to the same 4 minutes we have always waited for
+	HEALTH_INTERVAL = 1 * time.Second
+	HEALTH_TIMEOUT  = 5 * time.Second
+	HEALTH_RETRIES  = 240
+)
+
+//  Returns the interval between two probes
+func (h *HealthCheck) GetInterval() time.Duration {
+	return parseDuration(h.Interval, HEALTH_INTERVAL)
+}
+
+//  Returns the maximum duration of a single probe
+func (h *HealthCheck) GetTimeout() time.Duration {
+	return parseDuration(h.Timeout, HEALTH_TIMEOUT)
+}
+
+//  Returns the number of probes before declaring the service unhealthy
+func (h *HealthCheck) GetRetries() int {
+	if h.Retries > 0 {
+		return h.Retries
+	}
+	return HEALTH_RETRIES
+}
+
+func parseDuration(value string, fallback time.Duration) time.Duration {
+
+	if value == "" {
+		return fallback
+	}
+
+	response, err := time.ParseDuration(value)
+	if err != nil || response <= 0 {
+		log.WithField("value", value).Debug("Invalid health check duration, using default")
+		return fallback
+	}
+
+	return response
+}
+
+//  Runs the configured health check probe once against the service.
+//  Returns nil if the service is healthy.
+func (s *Service) Probe(docker *client.Client, ctx context.Context) error {
+
+	if s.HealthCheck == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.HealthCheck.GetTimeout())
+	defer cancel()
+
+	switch {
+	case s.HealthCheck.HTTP != "":
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Address+s.HealthCheck.HTTP, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+
+		return nil
+
+	case s.HealthCheck.TCP != 0:
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("localhost:%d", s.HealthCheck.TCP))
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+
+	case s.HealthCheck.SQL != "":
+
+		//  Connect over the unix socket, so that no password is required
+		command := []string{
+			"psql",
+			"-p", fmt.Sprint(s.Port),
+			"-U", fmt.Sprint(s.Environment["postgres_user"]),
+			"-d", "postgres",
+			"-tAc", s.HealthCheck.SQL,
+		}
+
+		return s.probeExec(docker, ctx, command)
+
+	case len(s.HealthCheck.Exec) > 0:
+
+		return s.probeExec(docker, ctx, s.HealthCheck.Exec)
+	}
+
+	return nil
+}
+
+//  Runs the command inside service's container, and expects exit code 0
+func (s *Service) probeExec(docker *client.Client, ctx context.Context, command []string) error {
+
+	if s.NoContainer || s.ID == "" {
+		return errors.New("no container to execute the health check in")
+	}
+
+	response, err := s.Exec(docker, ctx, command)
+	if err != nil {
+		return err
+	}
+
+	attached, err := docker.ContainerExecAttach(ctx, response.ID, types.ExecStartCheck{})
+	if err != nil {
+		return err
+	}
+	defer attached.Close()
+
+	var output bytes.Buffer
+	if _, err := stdcopy.StdCopy(&output, &output, attached.Reader); err != nil {
+		return err
+	}
+
+	result, err := docker.ContainerExecInspect(ctx, response.ID)
+	if err != nil {
+		return err
+	}
+
+	if result.ExitCode != 0 {
+		return fmt.Errorf("exit code %d: %s", result.ExitCode, strings.TrimSpace(output.String()))
+	}
+
+	return nil
+}
+
+//  Returns an error if the service's container has crashed,
+//  and therefore, will never pass it's health check.
+func (s *Service) Crashed(docker *client.Client, ctx context.Context) error {
+
+	if s.NoContainer || s.ID == "" {
+		return nil
+	}
+
+	data, err := docker.ContainerInspect(ctx, s.ID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case data.State.Status == "exited" || data.State.Status == "dead":
+		return fmt.Errorf("container %s with exit code %d", data.State.Status, data.State.ExitCode)
+	case data.State.Restarting && data.RestartCount >= 3:
+		return fmt.Errorf("container restarted %d times", data.RestartCount)
+	}
+
+	return nil
+}
+
+//  Fetches the last lines of service's container logs, including stderr
+func (s *Service) Tail(docker *client.Client, ctx context.Context, lines int) (string, error) {
+
+	log.WithFields(logrus.Fields{
+		"type":      "container",
+		"component": s.Name,
+	}).Debug("Fetching last logs")
+
+	out, err := docker.ContainerLogs(ctx, s.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       fmt.Sprint(lines),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	var response bytes.Buffer
+	if _, err := stdcopy.StdCopy(&response, &response, out); err != nil {
+		return "", err
+	}
+
+	return response.String(), nil
+}
@@ -0,0 +1,275 @@
+package nhost
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/sirupsen/logrus"
+)
+
+//  how often log files are checked for new lines, while following them
+const LOG_POLL_INTERVAL = 500 * time.Millisecond
+
+//  Location of logs of processes running outside containers,
+//  like functions and the proxy
+func LogsDir() string {
+	return filepath.Join(DOT_NHOST, "logs")
+}
+
+//  Returns the log file of a process running outside containers
+func LogPath(name string) string {
+	return filepath.Join(LogsDir(), name+".log")
+}
+
+//  Creates a fresh log file for a process running outside containers.
+//  Every line written to it is prefixed with it's timestamp,
+//  the same way docker does for container logs.
+func CreateLog(name string) (io.WriteCloser, error) {
+
+	if err := os.MkdirAll(LogsDir(), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(LogPath(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &logWriter{file: file}, nil
+}
+
+//  Writes timestamped lines to a log file
+type logWriter struct {
+	file   *os.File
+	buffer []byte
+	mutex  sync.Mutex
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buffer = append(w.buffer, p...)
+
+	//  Only write complete lines, so that every one gets a timestamp
+	for {
+		index := bytes.IndexByte(w.buffer, '\n')
+		if index < 0 {
+			break
+		}
+
+		if err := w.writeLine(w.buffer[:index]); err != nil {
+			return 0, err
+		}
+
+		w.buffer = w.buffer[index+1:]
+	}
+
+	return len(p), nil
+}
+
+func (w *logWriter) writeLine(line []byte) error {
+	_, err := fmt.Fprintf(w.file, "%s %s\n", time.Now().UTC().Format(time.RFC3339Nano), bytes.TrimRight(line, "\r"))
+	return err
+}
+
+//  Writes the last incomplete line, and closes the file
+func (w *logWriter) Close() error {
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.buffer) > 0 {
+		if err := w.writeLine(w.buffer); err != nil {
+			w.file.Close()
+			return err
+		}
+		w.buffer = nil
+	}
+
+	return w.file.Close()
+}
+
+//  Reads the logs of service's container, including stderr,
+//  and passes every line to emit, until they end or the context is cancelled.
+func (s *Service) Logs(cli *client.Client, ctx context.Context, options LogOptions, emit func(LogLine)) error {
+
+	log.WithFields(logrus.Fields{
+		"type":      "container",
+		"component": s.Name,
+	}).Debug("Fetching logs")
+
+	config := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     options.Follow,
+	}
+
+	if options.Tail >= 0 {
+		config.Tail = fmt.Sprint(options.Tail)
+	}
+
+	if !options.Since.IsZero() {
+		config.Since = fmt.Sprintf("%d.%09d", options.Since.Unix(), options.Since.Nanosecond())
+	}
+
+	out, err := cli.ContainerLogs(ctx, s.ID, config)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	//  Logs of containers without a TTY are multiplexed,
+	//  with separate frames for stdout and stderr
+	reader, writer := io.Pipe()
+	go func() {
+		var err error
+		if s.Config != nil && s.Config.Tty {
+			_, err = io.Copy(writer, out)
+		} else {
+			_, err = stdcopy.StdCopy(writer, writer, out)
+		}
+		writer.CloseWithError(err)
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var previous time.Time
+	for scanner.Scan() {
+		line := parseLogLine(s.Name, scanner.Text(), previous)
+		previous = line.Time
+		emit(line)
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+
+	return nil
+}
+
+//  Reads the log file of a process running outside containers,
+//  and passes every line to emit. While following, the file is polled
+//  for new lines until the context is cancelled.
+func ReadLog(ctx context.Context, name string, options LogOptions, emit func(LogLine)) error {
+
+	file, err := os.Open(LogPath(name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var (
+		history  []LogLine
+		previous time.Time
+		offset   int64
+		pending  string
+	)
+
+	reader := bufio.NewReader(file)
+
+	//  Returns false once all complete lines have been read
+	next := func() (LogLine, bool, error) {
+		text, err := reader.ReadString('\n')
+		offset += int64(len(text))
+		if err != nil {
+			pending += text
+			return LogLine{}, false, err
+		}
+
+		line := parseLogLine(name, strings.TrimRight(pending+text, "\r\n"), previous)
+		previous = line.Time
+		pending = ""
+		return line, true, nil
+	}
+
+	for {
+		line, ok, err := next()
+		if !ok {
+			if err != io.EOF {
+				return err
+			}
+			break
+		}
+
+		if line.Time.Before(options.Since) {
+			continue
+		}
+
+		history = append(history, line)
+		if options.Tail >= 0 && len(history) > options.Tail {
+			history = history[1:]
+		}
+	}
+
+	for _, line := range history {
+		emit(line)
+	}
+
+	if !options.Follow {
+		return nil
+	}
+
+	for {
+		line, ok, err := next()
+		if ok {
+			emit(line)
+			continue
+		}
+
+		if err != io.EOF {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(LOG_POLL_INTERVAL):
+		}
+
+		//  The file is re-created every time the app starts
+		if info, err := file.Stat(); err == nil && info.Size() < offset {
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			reader.Reset(file)
+			offset, pending = 0, ""
+		}
+	}
+}
+
+//  Splits the timestamp, added by docker or CreateLog, from the line.
+//  Lines without a valid timestamp inherit the previous one.
+func parseLogLine(service, text string, previous time.Time) LogLine {
+
+	line := LogLine{
+		Service: service,
+		Time:    previous,
+		Text:    text,
+	}
+
+	payload := strings.SplitN(text, " ", 2)
+	if created, err := time.Parse(time.RFC3339Nano, payload[0]); err == nil {
+		line.Time = created
+		line.Text = ""
+		if len(payload) > 1 {
+			line.Text = payload[1]
+		}
+	}
+
+	return line
+}
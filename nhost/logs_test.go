@@ -0,0 +1,55 @@
+package nhost
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLogs(t *testing.T) {
+
+	DOT_NHOST = t.TempDir()
+
+	file, err := CreateLog("functions")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//  Lines may be written in parts
+	fmt.Fprint(file, "first\nsec")
+	fmt.Fprint(file, "ond\nthird\n")
+	fmt.Fprint(file, "last")
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options LogOptions
+		want    []string
+	}{
+		{name: "all", options: LogOptions{Tail: -1}, want: []string{"first", "second", "third", "last"}},
+		{name: "tail", options: LogOptions{Tail: 2}, want: []string{"third", "last"}},
+		{name: "since", options: LogOptions{Tail: -1, Since: time.Now().Add(time.Minute)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var got []string
+			if err := ReadLog(context.Background(), "functions", tt.options, func(line LogLine) {
+				if line.Service != "functions" || line.Time.IsZero() {
+					t.Errorf("ReadLog() line = %+v, want service and time", line)
+				}
+				got = append(got, line.Text)
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ReadLog() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,21 +5,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
 
 	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	"github.com/nhost/cli/util"
@@ -101,16 +98,10 @@ func (config *Configuration) Save() error {
 //  Get the expected current DotNhost directory as per git branch head
 func GetDotNhost() (string, error) {
 
-	//  set default branch name
-	branch := "main"
-
 	//  If the current directory is a git repository,
-	//  then read the branch name from HEAD
-	if pathExists(GIT_DIR) {
-		branch = GetCurrentBranch()
-	}
-
-	return filepath.Join(util.WORKING_DIR, ".nhost", branch), nil
+	//  then read the branch name from HEAD,
+	//  otherwise use the default branch name
+	return filepath.Join(util.WORKING_DIR, ".nhost", GetBranch()), nil
 }
 
 func Env() ([]string, error) {
@@ -324,17 +315,47 @@ func (c *Configuration) Wrap() error {
 		return err
 	}
 
-	//  Parse additional services against supplied payload
-	for _, name := range SERVICES {
+	if parsed.Services == nil {
+		parsed.Services = make(map[string]*Service)
+	}
 
-		//  If no such service exists in the environment configuration,
-		//  then initialize the structure for it
+	//  If no such service exists in the environment configuration,
+	//  then initialize the structure for it
+	for _, name := range SERVICES {
 		if parsed.Services[name] == nil {
 			parsed.Services[name] = &Service{}
 		}
+	}
+
+	//  Ports assigned on previous runs
+	ports, err := LoadPorts()
+	if err != nil {
+		log.WithField("path", PortsPath()).Debug(err)
+	}
+
+	//  Parse Nhost, as well as user-defined services, against supplied payload
+	for name := range parsed.Services {
+
+		//  Skip empty entries in config.yaml
+		if parsed.Services[name] == nil {
+			delete(parsed.Services, name)
+			continue
+		}
 
 		if c.Services[name] != nil && c.Services[name].ID != "" {
 			parsed.Services[name].ID = c.Services[name].ID
+
+			//  Keep the port of existing container, unless a custom one is mentioned,
+			//  so that it's configuration doesn't change on every run.
+			if parsed.Services[name].Port == 0 {
+				parsed.Services[name].Port = c.Services[name].Port
+			}
+		}
+
+		//  Reuse the port assigned on previous runs,
+		//  so that addresses of services don't change on every run.
+		if parsed.Services[name].Port == 0 {
+			parsed.Services[name].Port = ports.reuse(name)
 		}
 
 		parsed.Services[name].Name = GetContainerName(name)
@@ -347,102 +368,15 @@ func (c *Configuration) Wrap() error {
 			}
 		*/
 
-		switch name {
-		case "minio":
-
-			if parsed.Services[name].Port == 0 {
-				parsed.Services[name].Port = util.GetPort(8200, 8500)
-			}
-
-			if parsed.Services[name].Version == nil {
-				parsed.Services[name].Version = "RELEASE.2021-09-24T00-24-24Z"
-			}
-
-			if parsed.Services[name].Image == "" {
-				parsed.Services[name].Image = "minio/minio"
-			}
-
-		case "mailhog":
-
-			if parsed.Services[name].Port == 0 {
-				parsed.Services[name].Port = util.GetPort(8800, 8900)
-			}
-
-			if parsed.Services[name].Version == nil {
-				parsed.Services[name].Version = "v1.0.1"
-			}
-
-			if parsed.Services[name].Image == "" {
-				parsed.Services[name].Image = "docker.io/mailhog/mailhog"
-			}
-
-		case "auth":
-
-			if parsed.Services[name].Port == 0 {
-				parsed.Services[name].Port = util.GetPort(9000, 9100)
-			}
-
-			if parsed.Services[name].Version == nil {
-				parsed.Services[name].Version = "0.6.3"
-			}
-
-			if parsed.Services[name].Image == "" {
-				parsed.Services[name].Image = "docker.io/nhost/hasura-auth"
-			}
-
-			if parsed.Services[name].HealthEndpoint == "" {
-				parsed.Services[name].HealthEndpoint = "/healthz"
-			}
-
-		case "storage":
-
-			if parsed.Services[name].Port == 0 {
-				parsed.Services[name].Port = util.GetPort(8501, 8799)
-			}
-
-			if parsed.Services[name].Version == nil {
-				parsed.Services[name].Version = "0.2.2"
-			}
-
-			if parsed.Services[name].Image == "" {
-				parsed.Services[name].Image = "docker.io/nhost/hasura-storage"
-			}
-
-			if parsed.Services[name].HealthEndpoint == "" {
-				parsed.Services[name].HealthEndpoint = "/healthz"
-			}
-
-		case "postgres":
-
-			if parsed.Services[name].Port == 0 {
-				parsed.Services[name].Port = util.GetPort(5000, 5999)
-			}
-
-			if parsed.Services[name].Version == nil {
-				parsed.Services[name].Version = "12-v0.0.6"
-			}
-
-			if parsed.Services[name].Image == "" {
-				parsed.Services[name].Image = "docker.io/nhost/postgres"
-			}
-
-		case "hasura":
-
-			if parsed.Services[name].Port == 0 {
-				parsed.Services[name].Port = util.GetPort(9200, 9300)
-			}
-
-			if parsed.Services[name].Version == nil {
-				parsed.Services[name].Version = "v2.2.0"
-			}
-
-			if parsed.Services[name].Image == "" {
-				parsed.Services[name].Image = "docker.io/hasura/graphql-engine"
-			}
+		if definition, ok := DEFINITIONS[name]; ok {
+			definition.wrap(parsed.Services[name])
+		} else if err := parsed.Services[name].wrapCustom(name); err != nil {
+			return err
+		}
 
-			if parsed.Services[name].HealthEndpoint == "" {
-				parsed.Services[name].HealthEndpoint = "/healthz"
-			}
+		//	Backward compatibility for `health_endpoint`
+		if parsed.Services[name].HealthCheck == nil && parsed.Services[name].HealthEndpoint != "" {
+			parsed.Services[name].HealthCheck = &HealthCheck{HTTP: parsed.Services[name].HealthEndpoint}
 		}
 
 		//	If no custom address is mentioned,
@@ -460,6 +394,15 @@ func (c *Configuration) Wrap() error {
 
 		//  Initialize configuration for the service
 		parsed.Services[name].InitConfig()
+
+		if IsCustom(name) {
+			if err := parsed.Services[name].initCustomConfig(); err != nil {
+				return err
+			}
+		}
+
+		//  Label the container with the app it belongs to
+		parsed.Services[name].Config.Labels = Labels(name)
 	}
 
 	//  update the environment configuration
@@ -526,8 +469,13 @@ func (s *Service) Run(client *client.Client, ctx context.Context, networkID stri
 			//  Save the it's ID for future use
 			s.ID = service.ID
 
-			//  Connect the newly created container to Nhost docker network
-			if err := client.NetworkConnect(ctx, networkID, s.ID, nil); err != nil {
+			//  Connect the newly created container to Nhost docker network.
+			//  Legacy container name is added as an alias,
+			//  so that addresses in older config.yaml files keep working.
+			endpoint := &network.EndpointSettings{
+				Aliases: []string{strings.Join([]string{LEGACY_PREFIX, s.Config.Labels[LABEL_SERVICE]}, "_")},
+			}
+			if err := client.NetworkConnect(ctx, networkID, s.ID, endpoint); err != nil {
 				return err
 			}
 
@@ -551,28 +499,6 @@ func (s *Service) Run(client *client.Client, ctx context.Context, networkID stri
 	return nil
 }
 
-//  Fetches container's configuration, mount points and host configuration,
-//  and validates them against configuration initialized by Nhost for it's respective service.
-func (s *Service) Inspect(client *client.Client, ctx context.Context) error {
-
-	log.WithFields(logrus.Fields{
-		"type":      "container",
-		"component": s.Name,
-	}).Debug("Inspecting")
-
-	data, err := client.ContainerInspect(ctx, s.ID)
-	if err != nil {
-		return err
-	}
-
-	//  Validate the fetched configuration against the loaded one
-	if data.Config == s.Config && data.HostConfig == s.HostConfig {
-		return nil
-	}
-
-	return errors.New("invalid configuration")
-}
-
 //  Checks whether the service's container already exists. Returns container ID string if true.
 func (s *Service) Exists(client *client.Client, ctx context.Context) string {
 
@@ -581,10 +507,8 @@ func (s *Service) Exists(client *client.Client, ctx context.Context) string {
 		"component": s.Name,
 	}).Debug("Searching")
 
-	f := filters.NewArgs(filters.KeyValuePair{
-		Key:   "name",
-		Value: s.Name,
-	})
+	f := ProjectFilter()
+	f.Add("label", fmt.Sprintf("%s=%s", LABEL_SERVICE, s.Config.Labels[LABEL_SERVICE]))
 
 	response, err := client.ContainerList(ctx, types.ContainerListOptions{
 		All:     true,
@@ -597,14 +521,19 @@ func (s *Service) Exists(client *client.Client, ctx context.Context) string {
 	return ""
 }
 
-func (s *Service) Healthz() bool {
+//  Returns the current state of service's container. Example: running, exited.
+func (s *Service) State(client *client.Client, ctx context.Context) (string, error) {
+
+	if s.ID == "" {
+		return "missing", nil
+	}
 
-	resp, err := http.Get(s.Address + s.HealthEndpoint)
+	data, err := client.ContainerInspect(ctx, s.ID)
 	if err != nil {
-		return false
+		return "", err
 	}
 
-	return resp.StatusCode == 200
+	return data.State.Status, nil
 }
 
 func (s *Service) InitConfig() {
@@ -686,14 +615,6 @@ func (config *Configuration) Init(port string) error {
 
 	log.Debug("Configuring services")
 
-	//  segregate configurations for different services
-	postgresConfig := config.Services["postgres"]
-	hasuraConfig := config.Services["hasura"]
-	storageConfig := config.Services["storage"]
-	authConfig := config.Services["auth"]
-	minioConfig := config.Services["minio"]
-	mailhogConfig := config.Services["mailhog"]
-
 	//  load .env.development
 	devVars, _ := Env()
 
@@ -701,368 +622,38 @@ func (config *Configuration) Init(port string) error {
 	dataTarget, _ := filepath.Rel(util.WORKING_DIR, DOT_NHOST)
 	log.WithField("service", "data").Debugln("Mounting data from ", dataTarget)
 
-	//  create mount point if it doesn't exist
-	sourceDir := filepath.Join(DOT_NHOST, "db_data")
-	targetDir := "/var/lib/postgresql/data"
-
-	if err := os.MkdirAll(sourceDir, os.ModePerm); err != nil {
-		return err
-	}
-
-	postgresConfig.Config.Cmd = []string{"-p", fmt.Sprint(config.Services["postgres"].Port)}
-	postgresConfig.HostConfig.Binds = []string{fmt.Sprintf("%s:%s:Z", sourceDir, targetDir)}
-
-	var pgUser, pgPass string
-
-	//  append service specific environment variables
-	for key, value := range postgresConfig.Environment {
-		postgresConfig.Config.Env = append(postgresConfig.Config.Env, fmt.Sprintf("%v=%v", strings.ToUpper(key), value))
-
-		switch strings.ToLower(key) {
-		case "postgres_user":
-			pgUser = value.(string)
-		case "postgres_password":
-			pgPass = value.(string)
-		}
-	}
-
-	//
-	//	Backward compatibility for CLI < v0.6.
-	//
-	//	If the environment interface is nil,
-	//	then initialize a blank one.
-	if config.Services["postgres"].Environment == nil {
-		config.Services["postgres"].Environment = make(map[string]interface{})
-	}
-	//
-	//	If credentials are not available in config.yaml,
-	//	add the default ones.
-	if pgUser == "" {
-		config.Services["postgres"].Environment["postgres_user"] = DB_USER
-		postgresConfig.Config.Env = append(postgresConfig.Config.Env, fmt.Sprintf("%v=%v", strings.ToUpper("postgres_user"), DB_USER))
-	}
-
-	if pgPass == "" {
-		config.Services["postgres"].Environment["postgres_password"] = DB_PASSWORD
-		postgresConfig.Config.Env = append(postgresConfig.Config.Env, fmt.Sprintf("%v=%v", strings.ToUpper("postgres_password"), DB_PASSWORD))
-	}
-
-	//	Update the service address
-	config.Services["postgres"].Address = GetAddress(config.Services["postgres"])
-
-	//  prepare env variables for following container
-	containerVariables := []string{
-		fmt.Sprintf("HASURA_GRAPHQL_SERVER_PORT=%v", config.Services["hasura"].Port),
-		fmt.Sprintf("HASURA_GRAPHQL_DATABASE_URL=%v", config.Services["postgres"].Address),
-		"HASURA_GRAPHQL_ENABLE_CONSOLE=false",
-		"HASURA_GRAPHQL_ENABLED_LOG_TYPES=startup, http-log, webhook-log, websocket-log, query-log",
-		fmt.Sprintf("HASURA_GRAPHQL_MIGRATIONS_SERVER_TIMEOUT=%d", 20),
-		fmt.Sprintf("HASURA_GRAPHQL_NO_OF_RETRIES=%d", 20),
-		"HASURA_GRAPHQL_UNAUTHORIZED_ROLE=public",
-	}
-
-	//	Append .env.development variables
-	containerVariables = append(containerVariables, devVars...)
-
-	//  Append service specific environment variables
-	for key, value := range hasuraConfig.Environment {
-		containerVariables = append(containerVariables, fmt.Sprintf("%v=%v", strings.ToUpper(key), value))
-	}
-
-	//  Append network based runtime variables,
-	//  to allow NHOST FUNCTIONS, and other addresses,
-	//	to be reachable from Hasura Event Triggers.
-	//  This is being done over here, because development proxy port is required.
-	containerVariables = append(
-		containerVariables,
-		util.MapToStringArray(util.RuntimeVars(port, true))...,
-	)
-
-	/*
-		//  create mount points if they doesn't exist
-		mountPoints = []mount.Mount{
-				{
-					Type:   mount.TypeBind,
-					Source: MIGRATIONS_DIR,
-					Target: "/hasura-migrations",
-				},
-		}
-
-					//  parse the metadata directory tree
-					meta_files, err := ioutil.ReadDir(METADATA_DIR)
-					if err != nil {
-						status.Errorln("Failed to parse the tree of metadata directory")
-						return err
-					}
-
-					//  mount the metadata directory if meta files exist
-					if len(meta_files) > 0 {
-						mountPoints = append(mountPoints, mount.Mount{
-							Type:   mount.TypeBind,
-							Source: METADATA_DIR,
-							Target: "/hasura-metadata",
-						})
-					}
-
-				for _, mountPoint := range mountPoints {
-					if err := os.MkdirAll(mountPoint.Source, os.ModePerm); err != nil {
-						return err
-					}
-				}
-
-			//hasuraConfig.HostConfig.Mounts = mountPoints
-	*/
-	//	hasuraConfig.HostConfig.Binds = []string{ENV_FILE + ":/env_file"}
-	//	hasuraConfig.Config.Cmd = append(hasuraConfig.Config.Cmd, "export", "$(grep -v '^#' /env_file | xargs)")
-	hasuraConfig.Config.Env = containerVariables
-
-	//  create mount points if they doesn't exit
-	mountPoints := []mount.Mount{
-		{
-			Type:   mount.TypeBind,
-			Source: filepath.Join(DOT_NHOST, "minio", "data"),
-			Target: "/data",
-		},
-		{
-			Type:   mount.TypeBind,
-			Source: filepath.Join(DOT_NHOST, "minio", "config"),
-			Target: "/.minio",
-		},
+	//  Default values come first, since addresses of services depend on them
+	for _, name := range SERVICES {
+		DEFINITIONS[name].setDefaults(config.Services[name])
 	}
 
-	for _, mountPoint := range mountPoints {
-		if err := os.MkdirAll(mountPoint.Source, os.ModePerm); err != nil {
+	//  configure Nhost services from their definitions
+	for _, name := range SERVICES {
+		if err := DEFINITIONS[name].configure(config, config.Services[name], port, devVars); err != nil {
 			return err
 		}
 	}
 
-	minioConfig.HostConfig.Mounts = mountPoints
-
-	var minioUser, minioPass string
-
-	//  append service specific environment variables
-	for key, value := range minioConfig.Environment {
-		minioConfig.Config.Env = append(minioConfig.Config.Env, fmt.Sprintf("%v=%v", strings.ToUpper(key), value))
-
-		switch strings.ToLower(key) {
-		case "minio_root_user":
-			minioUser = value.(string)
-		case "minio_root_password":
-			minioPass = value.(string)
+	//  configure user-defined services
+	for name, service := range config.Services {
+		if IsCustom(name) && !service.NoContainer {
+			if err := service.initCustom(name, port); err != nil {
+				return err
+			}
 		}
 	}
 
-	//
-	//	Backward compatibility for CLI < v0.6.
-	//
-	//	If the environment interface is nil,
-	//	then initialize a blank one.
-	if config.Services["minio"].Environment == nil {
-		config.Services["minio"].Environment = make(map[string]interface{})
-	}
-	//
-	//	If credentials are not available in config.yaml,
-	//	add the default ones.
-	if minioUser == "" {
-		config.Services["minio"].Environment["minio_root_user"] = MINIO_USER
-		minioConfig.Config.Env = append(minioConfig.Config.Env, fmt.Sprintf("%v=%v", strings.ToUpper("minio_root_user"), MINIO_USER))
-	}
-
-	if minioPass == "" {
-		config.Services["minio"].Environment["minio_root_password"] = MINIO_PASSWORD
-		minioConfig.Config.Env = append(minioConfig.Config.Env, fmt.Sprintf("%v=%v", strings.ToUpper("minio_root_password"), MINIO_PASSWORD))
-	}
-
-	//	Update the service address
-	config.Services["minio"].Address = GetAddress(config.Services["minio"])
-
-	minioConfig.Config.Cmd = []string{
-		"-c",
-		fmt.Sprintf(`mkdir -p /data/nhost && /opt/bin/minio server --address :%v /data`, config.Services["minio"].Port),
-	}
-
-	//User:  "999:1001",
-	minioConfig.Config.Entrypoint = []string{"sh"}
-
-	//  prepare env variables for following container
-	containerVariables = []string{
-		fmt.Sprintf("HASURA_GRAPHQL_DATABASE_URL=%v", config.Services["postgres"].Address),
-		fmt.Sprintf(`HASURA_GRAPHQL_GRAPHQL_URL=http://%s:%v/v1/graphql`, config.Services["hasura"].Name, config.Services["hasura"].Port),
-		fmt.Sprintf("AUTH_PORT=%v", config.Services["auth"].Port),
-		fmt.Sprintf("AUTH_SERVER_URL=http://localhost:%v/v1/auth", port),
-		fmt.Sprintf("AUTH_CLIENT_URL=%v", config.Auth["client_url"]),
-
-		//  set the defaults
-		"AUTH_LOG_LEVEL=info",
-		"AUTH_HOST=0.0.0.0",
-	}
-
-	//	Append runtime variables
-	containerVariables = append(
-		containerVariables,
-		util.MapToStringArray(util.RuntimeVars(port, true))...,
-	)
-
-	//  append social auth credentials and other env vars
-	containerVariables = append(containerVariables, ParseEnvVarsFromConfig(config.Auth, "AUTH")...)
-
-	//  append service specific environment variables
-	for key, value := range authConfig.Environment {
-		containerVariables = append(containerVariables, fmt.Sprintf("%v=%v", strings.ToUpper(key), value))
-	}
-
-	//  create mount point if it doesn't exit
-	customMountPoint := filepath.Join(DOT_NHOST, "custom", "keys")
-	if err := os.MkdirAll(customMountPoint, os.ModePerm); err != nil {
-		status.Errorln(fmt.Sprintf("Failed to create %s directory", customMountPoint))
-		return err
-	}
-
-	authVars := containerVariables
-	authConfig.Config.Env = containerVariables
-	authConfig.HostConfig.Mounts = []mount.Mount{
-		{
-			Type:   mount.TypeBind,
-			Source: filepath.Join(DOT_NHOST, "custom"),
-			Target: "/app/custom",
-		},
-		{
-			Type:   mount.TypeBind,
-			Source: EMAILS_DIR,
-			Target: "/app/email-templates",
-		},
-	}
-
-	//  prepare env variables for following container
-	containerVariables = []string{
-        fmt.Sprintf("BIND=:%d", config.Services["storage"].Port),
-		fmt.Sprintf("PUBLIC_URL=http://localhost:%d", config.Services["storage"].Port),
-
-		"HASURA_METADATA=1",
-		fmt.Sprintf(`HASURA_ENDPOINT=http://%s:%d/v1`, config.Services["hasura"].Name, config.Services["hasura"].Port),
-		fmt.Sprintf("HASURA_GRAPHQL_ADMIN_SECRET=%s", util.ADMIN_SECRET),
-
-		fmt.Sprintf("S3_ACCESS_KEY=%s", minioConfig.Environment["minio_root_user"]),
-		fmt.Sprintf("S3_SECRET_KEY=%s", minioConfig.Environment["minio_root_password"]),
-		"S3_BUCKET=nhost",
-
-		"POSTGRES_MIGRATIONS=1",
-        "POSTGRES_MIGRATIONS_SOURCE="+ GetAddress(config.Services["postgres"]),
-	}
-
-	//	Add S3 endpoint
-	if config.Services["minio"].NoContainer {
-		containerVariables = append(
-			containerVariables,
-			fmt.Sprintf("S3_ENDPOINT=%s", config.Services["minio"].Address),
-		)
-	} else {
-		containerVariables = append(
-			containerVariables,
-			fmt.Sprintf("S3_ENDPOINT=http://%s:%v", config.Services["minio"].Name, config.Services["minio"].Port),
-		)
-	}
-
-	//	Append runtime variables
-	containerVariables = append(
-		containerVariables,
-		util.MapToStringArray(util.RuntimeVars(port, true))...,
-	)
-
-	//  append service specific environment variables
-	for key, value := range storageConfig.Environment {
-		containerVariables = append(containerVariables, fmt.Sprintf("%v=%v", strings.ToUpper(key), value))
-	}
-
-	//  append storage env vars
-	containerVariables = append(containerVariables, ParseEnvVarsFromConfig(config.Storage, "STORAGE")...)
-	storageConfig.Config.Env = containerVariables
-	storageConfig.Config.Cmd = []string{
-		"serve",
-	}
-
-	//  prepare env variables for following container
-	//	containerVariables = appenddevVars(config.Auth["smtp"].(map[interface{}]interface{}), "SMTP")
-	var smtpPort int
-	for _, item := range authVars {
-		payload := strings.Split(item, "=")
-		if payload[0] == "AUTH_SMTP_PORT" {
-			smtpPort, _ = strconv.Atoi(payload[1])
-		}
-
-		//	If the SMTP server address inside config.yaml
-		//	doesn't match the container name,
-		//	i.e. it is a custom server address,
-		//	then don't launch the mailhog container
-		if payload[0] == "AUTH_SMTP_HOST" && payload[1] != GetContainerName("mailhog") {
-			log.WithField("service", "mailhog").Debug("Disabling container launch")
-			config.Services["mailhog"].NoContainer = true
+	//  Finally, fingerprint the generated configuration of every service,
+	//  to detect changes against existing containers
+	for _, service := range config.Services {
+		if service.Config != nil && service.Config.Labels != nil {
+			service.Config.Labels[LABEL_HASH] = service.Hash()
 		}
 	}
 
-	//	If the SMTP port is busy,
-	//	choose a random one
-	if !util.PortAvailable(strconv.Itoa(smtpPort)) {
-		log.WithField("component", "smtp").Errorf("Port %v not available", smtpPort)
-		log.WithField("component", "smtp").Info("Change your SMTP port in ./nhost/config.yaml")
-		return fmt.Errorf("SMTP port %v not available", smtpPort)
-		/*
-			smtpPort = util.GetPort(1000, 1999)
-			log.WithField("component", "smtp").Debugf("Running SMTP server on port %s", smtpPort)
-		*/
-	}
-
-	//  append service specific environment variables
-	for key, value := range mailhogConfig.Environment {
-		containerVariables = append(containerVariables, fmt.Sprintf("%v=%v", strings.ToUpper(key), value))
-	}
-
-	containerVariables = append(containerVariables,
-		fmt.Sprintf("MH_SMTP_BIND_ADDR=0.0.0.0:%v", smtpPort),
-		fmt.Sprintf("MH_UI_BIND_ADDR=0.0.0.0:%v", config.Services["mailhog"].Port),
-		fmt.Sprintf("MH_API_BIND_ADDR=0.0.0.0:%v", config.Services["mailhog"].Port),
-	)
-	mailhogConfig.Config.Env = containerVariables
-	mailhogConfig.HostConfig.PortBindings = map[nat.Port][]nat.PortBinding{
-		nat.Port(strconv.Itoa(smtpPort)): {{HostIP: "127.0.0.1",
-			HostPort: strconv.Itoa(smtpPort)}},
-		nat.Port(strconv.Itoa(config.Services["mailhog"].Port)): {{HostIP: "127.0.0.1",
-			HostPort: strconv.Itoa(config.Services["mailhog"].Port)}},
-	}
-	mailhogConfig.Config.ExposedPorts = nat.PortSet{
-		nat.Port(strconv.Itoa(smtpPort)):                        struct{}{},
-		nat.Port(strconv.Itoa(config.Services["mailhog"].Port)): struct{}{},
-	}
-
 	return nil
 }
 
-//  fetches the logs of a specific container
-//  and writes them to a log file
-func (s *Service) Logs(cli *client.Client, ctx context.Context) ([]byte, error) {
-
-	log.WithFields(logrus.Fields{
-		"type":      "container",
-		"component": s.Name,
-	}).Debug("Fetching logs")
-
-	var response []byte
-
-	options := types.ContainerLogsOptions{ShowStdout: true}
-
-	out, err := cli.ContainerLogs(ctx, s.ID, options)
-	if err != nil {
-		return response, err
-	}
-
-	response, err = io.ReadAll(out)
-	if err != nil {
-		return response, err
-	}
-
-	return response, nil
-}
-
 func (s *Service) Exec(docker *client.Client, ctx context.Context, command []string) (types.IDResponse, error) {
 
 	config := types.ExecConfig{
@@ -0,0 +1,106 @@
+package nhost
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/nhost/cli/util"
+	"gopkg.in/yaml.v2"
+)
+
+//  Location of ports assigned to services of the current branch
+func PortsPath() string {
+	return filepath.Join(DOT_NHOST, "ports.yaml")
+}
+
+//  Loads the ports assigned to services on previous runs.
+//  Returns an empty list if none have been assigned yet.
+func LoadPorts() (Ports, error) {
+
+	response := make(Ports)
+
+	data, err := ioutil.ReadFile(PortsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return response, nil
+		}
+		return response, err
+	}
+
+	if err := yaml.Unmarshal(data, &response); err != nil {
+		return make(Ports), err
+	}
+
+	return response, nil
+}
+
+//  Saves the assigned ports to .nhost/<branch>/ports.yaml
+func (p Ports) Save() error {
+
+	log.Debug("Saving assigned ports")
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(PortsPath()), os.ModePerm); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(PortsPath(), data, 0644)
+}
+
+//  Returns the port assigned to the service on previous runs,
+//  or 0, if it was never assigned, or it's taken by another process by now.
+func (p Ports) reuse(name string) int {
+
+	port, ok := p[name]
+	if !ok || port == 0 {
+		return 0
+	}
+
+	if !util.PortAvailable(fmt.Sprint(port)) {
+		status.Warnln(fmt.Sprintf("Port %d of %s is not available anymore, choosing a new one", port, name))
+		return 0
+	}
+
+	return port
+}
+
+//  Saves the ports of services with a local container, to be reused on next runs.
+//  Called once the containers have started, so that ports which were never bound aren't saved.
+func (c *Configuration) SavePorts() error {
+
+	ports, err := LoadPorts()
+	if err != nil {
+		log.WithField("path", PortsPath()).Debug(err)
+	}
+
+	return ports.update(c.Services)
+}
+
+//  Records the ports of all services with a local container,
+//  and saves them if they changed since the last run.
+func (p Ports) update(services map[string]*Service) error {
+
+	current := make(Ports)
+	for name, item := range p {
+		current[name] = item
+	}
+
+	for name, service := range services {
+		if !service.NoContainer && service.Port != 0 {
+			current[name] = service.Port
+		}
+	}
+
+	if reflect.DeepEqual(current, p) {
+		return nil
+	}
+
+	return current.Save()
+}
@@ -0,0 +1,58 @@
+package nhost
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestPorts(t *testing.T) {
+
+	DOT_NHOST = t.TempDir()
+
+	//  keep a port busy, to simulate a conflict with another process
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	busy := listener.Addr().(*net.TCPAddr).Port
+
+	ports, err := LoadPorts()
+	if err != nil || len(ports) != 0 {
+		t.Fatalf("LoadPorts() = %v, %v, want empty list", ports, err)
+	}
+
+	services := map[string]*Service{
+		"hasura":  {Port: 39200},
+		"auth":    {Port: busy},
+		"worker":  {},
+		"mailhog": {Port: 38800, NoContainer: true},
+	}
+
+	config := Configuration{Services: services}
+	if err := config.SavePorts(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := LoadPorts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (Ports{"hasura": 39200, "auth": busy}); !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved ports = %v, want %v", saved, want)
+	}
+
+	if got := saved.reuse("hasura"); got != 39200 {
+		t.Errorf("reuse(hasura) = %v, want 39200", got)
+	}
+
+	if got := saved.reuse("auth"); got != 0 {
+		t.Errorf("reuse(auth) = %v, want a new port to be chosen", got)
+	}
+
+	if got := saved.reuse("storage"); got != 0 {
+		t.Errorf("reuse(storage) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,57 @@
+package nhost
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//  Returns the directory of seeds for the database
+func SeedsDir() string {
+	return filepath.Join(SEEDS_DIR, DATABASE)
+}
+
+//  Loads the SQL seed files in the directory, in order of their names.
+//  Returns an empty list if the directory doesn't exist.
+func LoadSeeds(dir string) ([]Seed, error) {
+
+	var response []Seed
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return response, nil
+		}
+		return response, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".sql") {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return response, err
+		}
+
+		checksum := sha256.Sum256(data)
+		response = append(response, Seed{
+			Name:     file.Name(),
+			Path:     path,
+			SQL:      string(data),
+			Checksum: hex.EncodeToString(checksum[:]),
+		})
+	}
+
+	sort.SliceStable(response, func(i, j int) bool {
+		return response[i].Name < response[j].Name
+	})
+
+	return response, nil
+}
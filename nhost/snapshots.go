@@ -0,0 +1,187 @@
+package nhost
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nhost/cli/util"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+
+	//  timestamp suffix of snapshot names
+	SNAPSHOT_TIME_FORMAT = "20060102-150405"
+
+	SNAPSHOT_EXTENSION = ".tar.gz"
+)
+
+//  Returns the directory containing snapshots of the branch.
+//
+//  Git doesn't allow branch names starting with a dot,
+//  so .nhost/.snapshots never conflicts with data of a branch.
+func SnapshotsDir(branch string) string {
+	return filepath.Join(util.WORKING_DIR, ".nhost", ".snapshots", branch)
+}
+
+//  Returns the data directory of the branch. Example: .nhost/main
+func BranchDir(branch string) string {
+	return filepath.Join(util.WORKING_DIR, ".nhost", branch)
+}
+
+//  Archives data directories of the current branch into a timestamped snapshot.
+//  Containers using the data should be stopped before calling it.
+func CreateSnapshot(run DataRunner, name string) (Snapshot, error) {
+
+	branch := GetBranch()
+
+	if name == "" {
+		name = "snapshot"
+	}
+
+	if !regexp.MustCompile(`^[A-Za-z0-9_.-]+$`).MatchString(name) {
+		return Snapshot{}, fmt.Errorf("invalid snapshot name %q, only letters, digits, '.', '_' and '-' are allowed", name)
+	}
+
+	created := time.Now()
+	response := Snapshot{
+		Name:    fmt.Sprintf("%s-%s", name, created.Format(SNAPSHOT_TIME_FORMAT)),
+		Branch:  branch,
+		Created: created,
+	}
+	response.Path = filepath.Join(SnapshotsDir(branch), response.Name+SNAPSHOT_EXTENSION)
+
+	log.WithFields(logrus.Fields{
+		"branch":   branch,
+		"snapshot": response.Name,
+	}).Debug("Creating snapshot")
+
+	dirs := dataDirs(branch)
+	if len(dirs) == 0 {
+		return response, fmt.Errorf("no data found for branch %s", branch)
+	}
+
+	//  Snapshots directory is created by the CLI, so that it's user can delete the snapshots
+	if err := os.MkdirAll(filepath.Dir(response.Path), os.ModePerm); err != nil {
+		return response, err
+	}
+
+	archive, err := dataPath(response.Path)
+	if err != nil {
+		return response, err
+	}
+
+	script := `archive="$1"; branch="$2"; owner="$3"; shift 3
+tar -czpf "$archive" --numeric-owner -C "$branch" "$@" || { rm -f "$archive"; exit 1; }
+if [ -n "$owner" ]; then chown "$owner" "$archive"; fi`
+
+	if _, err := run(script, append([]string{archive, branch, dataOwner()}, dirs...)...); err != nil {
+		return response, err
+	}
+
+	info, err := os.Stat(response.Path)
+	if err != nil {
+		return response, err
+	}
+
+	response.Size = info.Size()
+	return response, nil
+}
+
+//  Lists snapshots of the branch, newest first
+func ListSnapshots(branch string) ([]Snapshot, error) {
+
+	var response []Snapshot
+
+	files, err := ioutil.ReadDir(SnapshotsDir(branch))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return response, nil
+		}
+		return response, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), SNAPSHOT_EXTENSION) {
+			continue
+		}
+
+		name := strings.TrimSuffix(file.Name(), SNAPSHOT_EXTENSION)
+		response = append(response, Snapshot{
+			Name:    name,
+			Branch:  branch,
+			Path:    filepath.Join(SnapshotsDir(branch), file.Name()),
+			Size:    file.Size(),
+			Created: snapshotTime(name, file.ModTime()),
+		})
+	}
+
+	sort.SliceStable(response, func(i, j int) bool {
+		return response[i].Created.After(response[j].Created)
+	})
+
+	return response, nil
+}
+
+//  Finds a snapshot of the branch by it's full name,
+//  or the latest one with given name, without timestamp.
+func FindSnapshot(branch, name string) (Snapshot, error) {
+
+	snapshots, err := ListSnapshots(branch)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	//  Snapshots are sorted newest first
+	for _, item := range snapshots {
+		if item.Name == name || strings.TrimSuffix(item.Name, "-"+item.Created.Format(SNAPSHOT_TIME_FORMAT)) == name {
+			return item, nil
+		}
+	}
+
+	return Snapshot{}, fmt.Errorf("no snapshot named %s found for branch %s", name, branch)
+}
+
+//  Replaces data directories of the current branch with contents of the snapshot.
+//  Containers using the data should be stopped before calling it.
+//
+//  Snapshot is extracted into a temporary directory first,
+//  so that a failure doesn't destroy the current data.
+func (s *Snapshot) Restore(run DataRunner) error {
+
+	log.WithFields(logrus.Fields{
+		"branch":   GetBranch(),
+		"snapshot": s.Name,
+	}).Debug("Restoring snapshot")
+
+	archive, err := dataPath(s.Path)
+	if err != nil {
+		return err
+	}
+
+	script := `rm -rf .restore && mkdir -p .restore
+tar -xzpf "$2" --numeric-owner -C .restore
+` + replaceDataScript
+
+	_, err = run(script, GetBranch(), archive)
+	return err
+}
+
+//  Parses the creation time from snapshot name,
+//  and falls back to the time of file modification.
+func snapshotTime(name string, fallback time.Time) time.Time {
+
+	if len(name) > len(SNAPSHOT_TIME_FORMAT) {
+		if created, err := time.ParseInLocation(SNAPSHOT_TIME_FORMAT, name[len(name)-len(SNAPSHOT_TIME_FORMAT):], time.Local); err == nil {
+			return created
+		}
+	}
+
+	return fallback
+}
@@ -0,0 +1,100 @@
+package nhost
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/nhost/cli/util"
+)
+
+//  Runs data scripts without a container, as the current user
+func localRunner(script string, args ...string) (string, error) {
+
+	var stderr bytes.Buffer
+	command := exec.Command("sh", append([]string{"-c", "set -e\n" + script, "sh"}, args...)...)
+	command.Dir = filepath.Join(util.WORKING_DIR, ".nhost")
+	command.Stderr = &stderr
+
+	output, err := command.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, stderr.String())
+	}
+
+	return string(output), nil
+}
+
+func TestSnapshots(t *testing.T) {
+
+	util.WORKING_DIR = t.TempDir()
+	GIT_DIR = filepath.Join(util.WORKING_DIR, ".git")
+
+	data := filepath.Join(BranchDir("main"), "db_data", "PG_VERSION")
+	if err := os.MkdirAll(filepath.Dir(data), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(data, []byte("12"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	//  Only the data is snapshotted
+	ports := filepath.Join(BranchDir("main"), "ports.yaml")
+	if err := ioutil.WriteFile(ports, []byte("hasura: 9201"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := CreateSnapshot(localRunner, "before-migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CreateSnapshot(localRunner, "../escape"); err == nil {
+		t.Error("CreateSnapshot() accepted an invalid name")
+	}
+
+	//  Destroy the data, and restore it from the snapshot
+	if err := ioutil.WriteFile(data, []byte("broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(BranchDir("main"), "db_data", "leftover"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(ports, []byte("hasura: 9202"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := FindSnapshot("main", "before-migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.Name != snapshot.Name || found.Size != snapshot.Size {
+		t.Errorf("FindSnapshot() = %+v, want %+v", found, snapshot)
+	}
+
+	if err := found.Restore(localRunner); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(data)
+	if err != nil || string(content) != "12" {
+		t.Errorf("restored data = %q, %v, want 12", content, err)
+	}
+
+	if util.PathExists(filepath.Join(BranchDir("main"), "db_data", "leftover")) {
+		t.Error("Restore() kept files created after the snapshot")
+	}
+
+	if content, err := ioutil.ReadFile(ports); err != nil || string(content) != "hasura: 9202" {
+		t.Errorf("ports.yaml after Restore() = %q, %v, want it to be kept", content, err)
+	}
+
+	snapshots, err := ListSnapshots("main")
+	if err != nil || len(snapshots) != 1 {
+		t.Errorf("ListSnapshots() = %v, %v, want 1 snapshot", snapshots, err)
+	}
+}
@@ -2,6 +2,7 @@ package nhost
 
 import (
 	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 )
@@ -108,15 +109,29 @@ type (
 
 	//  Nhost config.yaml root structure
 	Configuration struct {
+		ProjectName       string                      `yaml:"project_name,omitempty"`
 		MetadataDirectory string                      `yaml:"metadata_directory,omitempty"`
 		Services          map[string]*Service         `yaml:",omitempty"`
 		Auth              map[interface{}]interface{} `yaml:",omitempty"`
 		Storage           map[interface{}]interface{} `yaml:",omitempty"`
 		Version           int                         `yaml:",omitempty"`
 		Sessions          map[string]Session          `yaml:",omitempty"`
+		Branches          Branches                    `yaml:",omitempty"`
 		//  Environment       map[string]interface{} `yaml:",omitempty"`
 	}
 
+	//  Nhost config.yaml git branches structure
+	Branches struct {
+
+		//  branch whose data is copied into branches which have never run,
+		//  or "previous" for the branch checked out before
+		CopyFrom string `yaml:"copy_from,omitempty"`
+
+		//  warn on `nhost dev`, if data of deleted branches is larger than this.
+		//  Example: 500MB, 2GB
+		StaleDataLimit string `yaml:"stale_data_limit,omitempty"`
+	}
+
 	//  Nhost config.yaml authentication structure
 	Authentication struct {
 		Endpoints map[string]interface{} `yaml:",omitempty"`
@@ -140,12 +155,32 @@ type (
 		Config         *container.Config      `yaml:",omitempty"`
 		HostConfig     *container.HostConfig  `yaml:",omitempty"`
 		HealthEndpoint string                 `yaml:"health_endpoint,omitempty"`
+		HealthCheck    *HealthCheck           `yaml:"healthcheck,omitempty"`
 		Environment    map[string]interface{} `yaml:",omitempty"`
 
 		//	If custom address is mentioned,
 		//	do not launch the container
 		NoContainer bool `yaml:",omitempty"`
 
+		//  Following fields are only used by user-defined services.
+		//  Container port published on `port`, if different from it.
+		TargetPort int `yaml:"target_port,omitempty"`
+
+		//  Additional "[ip:]host:container" port mappings
+		Ports []string `yaml:",omitempty"`
+
+		//  Overrides the default command of the image
+		Command []string `yaml:",omitempty"`
+
+		//  "source:target" bind mounts
+		Volumes []string `yaml:",omitempty"`
+
+		//  Dev proxy routes
+		Routes []Route `yaml:",omitempty"`
+
+		//  Services which must be healthy before this one starts
+		DependsOn []string `yaml:"depends_on,omitempty"`
+
 		//  Channels are best thought of as queues (FIFO).
 		//  Therefore you can't really skip around.
 		//  We need a mutex to lock the service
@@ -158,6 +193,90 @@ type (
 		//	Handler func(http.ResponseWriter, *http.Request) `yaml:",omitempty"`
 	}
 
+	//  Nhost config.yaml service health check structure.
+	//  Only one of the probes (http, tcp, sql, exec) is used, in that order.
+	HealthCheck struct {
+
+		//  path requested on service address, expecting a 2xx response
+		HTTP string `yaml:"http,omitempty"`
+
+		//  host port expected to accept TCP connections
+		TCP int `yaml:"tcp,omitempty"`
+
+		//  query run with psql inside the container
+		SQL string `yaml:"sql,omitempty"`
+
+		//  command run inside the container, expecting exit code 0
+		Exec []string `yaml:"exec,omitempty"`
+
+		//  durations like "1s" or "500ms"
+		Interval string `yaml:"interval,omitempty"`
+		Timeout  string `yaml:"timeout,omitempty"`
+
+		Retries int `yaml:"retries,omitempty"`
+	}
+
+	//  Nhost config.yaml proxy route structure.
+	//  Requests to `destination` path of the dev proxy
+	//  are forwarded to `source` path of the service.
+	Route struct {
+		Name        string `yaml:",omitempty"`
+		Source      string `yaml:",omitempty"`
+		Destination string `yaml:",omitempty"`
+
+		//  not listed in the output of `nhost dev`
+		Hidden bool `yaml:",omitempty"`
+	}
+
+	//  .nhost/<branch>/ports.yaml information,
+	//  ports assigned to services by their names
+	Ports map[string]int
+
+	//  Options for reading logs of services
+	LogOptions struct {
+
+		//  keep reading new lines until cancelled
+		Follow bool
+
+		//  number of last lines to read, all of them if negative
+		Tail int
+
+		//  only read lines written after this time
+		Since time.Time
+	}
+
+	//  Single line of service logs
+	LogLine struct {
+		Service string
+		Time    time.Time
+		Text    string
+	}
+
+	//  SQL seed file
+	Seed struct {
+		Name     string
+		Path     string
+		SQL      string
+		Checksum string
+	}
+
+	//  Data directory of a branch in .nhost
+	BranchData struct {
+		Branch string
+		Path   string
+		Size   int64
+	}
+
+	//  Archived data directory of a branch,
+	//  stored in .nhost/.snapshots/<branch>
+	Snapshot struct {
+		Name    string
+		Branch  string
+		Path    string
+		Size    int64
+		Created time.Time
+	}
+
 	//  .nhost/nhost.yaml information
 	Information struct {
 		ProjectID string `yaml:"project_id,omitempty"`
@@ -165,6 +284,17 @@ type (
 		Name      string `yaml:",omitempty"`
 	}
 
+	//  .nhost/daemon.yaml information,
+	//  written by a detached development environment
+	Daemon struct {
+		PID     int       `yaml:"pid"`
+		Port    string    `yaml:"port"`
+		Branch  string    `yaml:"branch,omitempty"`
+		Status  string    `yaml:"status"`
+		Log     string    `yaml:"log"`
+		Started time.Time `yaml:"started"`
+	}
+
 	//  Nhost servers structure
 	Server struct {
 		ID          string
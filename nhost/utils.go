@@ -3,8 +3,14 @@ package nhost
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/nhost/cli/util"
+	"gopkg.in/yaml.v2"
 )
 
 func ParseEnvVarsFromConfig(payload map[interface{}]interface{}, prefix string) []string {
@@ -26,13 +32,177 @@ func GetContainerName(name string) string {
 	return strings.Join([]string{PREFIX, name}, "_")
 }
 
+//  Checks whether the given host is the container name of a service,
+//  either with the app prefix, or with the legacy "nhost" prefix.
+func IsContainerName(host, name string) bool {
+	return host == GetContainerName(name) || host == strings.Join([]string{LEGACY_PREFIX, name}, "_")
+}
+
+//  Derives the container and network prefix of the app,
+//  from `project_name` in config.yaml, or the app directory name.
+func GetPrefix() string {
+
+	var config struct {
+		ProjectName string `yaml:"project_name"`
+	}
+
+	name := filepath.Base(util.WORKING_DIR)
+
+	if data, err := ioutil.ReadFile(CONFIG_PATH); err == nil {
+		if err := yaml.Unmarshal(data, &config); err == nil && config.ProjectName != "" {
+			name = config.ProjectName
+		}
+	}
+
+	//  Docker only allows [a-zA-Z0-9][a-zA-Z0-9_.-] in names
+	name = regexp.MustCompile(`[^a-z0-9_.-]+`).ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.TrimLeft(name, "_.-")
+
+	if name == "" {
+		return LEGACY_PREFIX
+	}
+
+	return name
+}
+
+//  Returns the version of the hasura service mentioned in config.yaml,
+//  or the default one. Example: v2.2.0
+func HasuraVersion() string {
+
+	var config struct {
+		Services map[string]struct {
+			Version interface{} `yaml:"version"`
+		} `yaml:"services"`
+	}
+
+	if data, err := ioutil.ReadFile(CONFIG_PATH); err == nil {
+		if err := yaml.Unmarshal(data, &config); err == nil {
+			if service, ok := config.Services["hasura"]; ok && service.Version != nil {
+				return fmt.Sprint(service.Version)
+			}
+		}
+	}
+
+	return DEFINITIONS["hasura"].Version
+}
+
+//  Returns the docker labels identifying the given service of this app
+func Labels(service string) map[string]string {
+	return map[string]string{
+		LABEL_PROJECT: PREFIX,
+		LABEL_BRANCH:  GetBranch(),
+		LABEL_SERVICE: service,
+	}
+}
+
+//  Returns the docker filter matching all resources of this app
+func ProjectFilter() filters.Args {
+	return filters.NewArgs(filters.KeyValuePair{
+		Key:   "label",
+		Value: fmt.Sprintf("%s=%s", LABEL_PROJECT, PREFIX),
+	})
+}
+
+//  Returns the git branch of the app,
+//  defaulting to "main" outside of git repositories.
+func GetBranch() string {
+	if branch := GetCurrentBranch(); branch != "" {
+		return branch
+	}
+	return "main"
+}
+
+//  Returns the path of git HEAD file.
+func GetHEAD() string {
+	return filepath.Join(gitDir(), "HEAD")
+}
+
+//  Returns the git directory of the app.
+//  Supports git worktrees, where .git is a file pointing to the actual git directory.
+func gitDir() string {
+
+	data, err := ioutil.ReadFile(GIT_DIR)
+	if err != nil {
+		return GIT_DIR
+	}
+
+	gitdir := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(filepath.Dir(GIT_DIR), gitdir)
+	}
+
+	return gitdir
+}
+
+//  Returns the git directory shared by all worktrees, containing the refs.
+//  Same as the git directory, outside of worktrees.
+func CommonGitDir() string {
+
+	dir := gitDir()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "commondir"))
+	if err != nil {
+		return dir
+	}
+
+	common := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+
+	return common
+}
+
+//  Returns the names of local git branches,
+//  the way they are used for data directories in .nhost
+func LocalBranches() ([]string, error) {
+
+	var response []string
+	common := CommonGitDir()
+
+	heads := filepath.Join(common, "refs", "heads")
+	err := filepath.Walk(heads, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			response = append(response, filepath.Base(path))
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return response, err
+	}
+
+	//  Branches may also be packed, by `git gc`
+	packed, err := ioutil.ReadFile(filepath.Join(common, "packed-refs"))
+	if err != nil && !os.IsNotExist(err) {
+		return response, err
+	}
+
+	for _, line := range strings.Split(string(packed), "\n") {
+		payload := strings.Fields(line)
+		if len(payload) == 2 && strings.HasPrefix(payload[1], "refs/heads/") {
+			response = append(response, filepath.Base(payload[1]))
+		}
+	}
+
+	return response, nil
+}
+
 func GetCurrentBranch() string {
 
 	log.Debug("Fetching local git branch")
-	data, err := ioutil.ReadFile(filepath.Join(GIT_DIR, "HEAD"))
+	data, err := ioutil.ReadFile(GetHEAD())
 	if err != nil {
 		return ""
 	}
+
+	//  Detached HEAD only contains the commit hash
 	payload := strings.Split(string(data), " ")
+	if len(payload) < 2 {
+		return ""
+	}
+
 	return strings.TrimSpace(filepath.Base(payload[1]))
 }
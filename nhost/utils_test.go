@@ -0,0 +1,98 @@
+package nhost
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nhost/cli/util"
+)
+
+func TestGetPrefix(t *testing.T) {
+
+	defer func(dir, config string) { util.WORKING_DIR, CONFIG_PATH = dir, config }(util.WORKING_DIR, CONFIG_PATH)
+
+	tests := []struct {
+		name   string
+		dir    string
+		config string
+		want   string
+	}{
+		{name: "directory", dir: "my-app", want: "my-app"},
+		{name: "sanitized directory", dir: "My App (v2)", want: "my-app-v2-"},
+		{name: "project name", dir: "worktree", config: "project_name: shop\n", want: "shop"},
+		{name: "invalid project name", dir: "worktree", config: "project_name: ___\n", want: "nhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			util.WORKING_DIR = filepath.Join(t.TempDir(), tt.dir)
+			CONFIG_PATH = filepath.Join(t.TempDir(), "config.yaml")
+
+			if tt.config != "" {
+				if err := ioutil.WriteFile(CONFIG_PATH, []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := GetPrefix(); got != tt.want {
+				t.Errorf("GetPrefix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsContainerName(t *testing.T) {
+
+	defer func(prefix string) { PREFIX = prefix }(PREFIX)
+	PREFIX = "shop"
+
+	for host, want := range map[string]bool{
+		"shop_mailhog":  true,
+		"nhost_mailhog": true,
+		"smtp.example":  false,
+		"shop_postgres": false,
+	} {
+		if got := IsContainerName(host, "mailhog"); got != want {
+			t.Errorf("IsContainerName(%v) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestCommonGitDir(t *testing.T) {
+
+	defer func(dir string) { GIT_DIR = dir }(GIT_DIR)
+
+	root := t.TempDir()
+	common := filepath.Join(root, "app", ".git")
+	worktree := filepath.Join(common, "worktrees", "feature")
+
+	if err := os.MkdirAll(worktree, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	//  Worktrees point to their git directory, which points to the shared one
+	for path, content := range map[string]string{
+		filepath.Join(root, "feature", ".git"): "gitdir: " + worktree + "\n",
+		filepath.Join(worktree, "commondir"):   "../..\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	GIT_DIR = filepath.Join(root, "feature", ".git")
+	if got := CommonGitDir(); got != common {
+		t.Errorf("CommonGitDir() of a worktree = %s, want %s", got, common)
+	}
+
+	GIT_DIR = common
+	if got := CommonGitDir(); got != common {
+		t.Errorf("CommonGitDir() = %s, want %s", got, common)
+	}
+}
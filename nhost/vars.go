@@ -16,6 +16,16 @@ const (
 
 	MINIO_USER     = "minioaccesskey123123"
 	MINIO_PASSWORD = "minioaccesskey123123"
+
+	//  prefix used by CLI < v0.7 for all apps,
+	//  still resolvable on the app network for backward compatibility
+	LEGACY_PREFIX = "nhost"
+
+	//  docker labels identifying the resources of an app
+	LABEL_PROJECT = "io.nhost.project"
+	LABEL_BRANCH  = "io.nhost.branch"
+	LABEL_SERVICE = "io.nhost.service"
+	LABEL_HASH    = "io.nhost.hash"
 )
 
 var (
@@ -82,6 +92,12 @@ var (
 	//  path for .nhost/nhost.yaml file
 	INFO_PATH string
 
+	//  path for .nhost/daemon.yaml file
+	DAEMON_PATH string
+
+	//  path for .nhost/daemon.log file
+	DAEMON_LOG_PATH string
+
 	//  path for express NPM modules
 	NODE_MODULES_PATH string
 
@@ -106,10 +122,14 @@ func Init() {
 
 	INFO_PATH = filepath.Join(util.WORKING_DIR, ".nhost", "nhost.yaml")
 
+	//  state and output of detached development environment
+	DAEMON_PATH = filepath.Join(util.WORKING_DIR, ".nhost", "daemon.yaml")
+	DAEMON_LOG_PATH = filepath.Join(util.WORKING_DIR, ".nhost", "daemon.log")
+
 	DOT_NHOST, _ = GetDotNhost()
 
 	//  initialize the names of all Nhost services in the stack
-	SERVICES = []string{"hasura", "auth", "storage", "mailhog", "postgres", "minio"}
+	SERVICES = definitionNames()
 
 	//  find user's home directory
 	HOME, _ = os.UserHomeDir()
@@ -162,9 +182,9 @@ func Init() {
 	//  package repository to download latest release from
 	REPOSITORY = "nhost/cli"
 
-	//  initialize the project prefix
-	//	PREFIX = filepath.Base(util.WORKING_DIR)
-	PREFIX = "nhost"
+	//  initialize the project prefix,
+	//  so that several apps can run side by side
+	PREFIX = GetPrefix()
 
 	//	mandatorily required locations
 	LOCATIONS = Required{
@@ -196,6 +216,8 @@ func UpdateLocations(old, new string) {
 		&GIT_DIR,
 		&NODE_MODULES_PATH,
 		&WEB_DIR,
+		&DAEMON_PATH,
+		&DAEMON_LOG_PATH,
 	}...)
 
 	for _, item := range payload {
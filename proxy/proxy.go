@@ -3,6 +3,7 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -29,6 +30,9 @@ type Service struct {
 
 	//	Service specific logger
 	log logrus.Logger
+
+	//	Destination of request logs, if any
+	accessLog io.Writer
 }
 
 //	Issue proxy to all services attached to the server.
@@ -96,6 +100,10 @@ func (s *Service) Issue(mux *http.ServeMux, ctx context.Context) error {
 				"method":    r.Method,
 			}).Debug(r.URL.Path)
 
+			if s.accessLog != nil {
+				fmt.Fprintf(s.accessLog, "%s %s --> %s\n", r.Method, r.URL.Path, s.Name)
+			}
+
 			//	If the supplied context is not nil,
 			//	wrap the incoming request over the context
 			if ctx != nil {
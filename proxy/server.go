@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 
@@ -54,6 +55,9 @@ type ServerConfig struct {
 
 	//	(Optional) Do not delete the call logs on server shutdown.
 	SaveLogs bool
+
+	//	(Optional) Destination to log every proxied request to.
+	AccessLog io.Writer
 }
 
 //	Intializes and returns a new functions server.
@@ -102,6 +106,7 @@ func New(config *ServerConfig) *Server {
 //	Attaches a service to this server.
 func (s *Server) AddService(service *Service) {
 	service.log = *s.log
+	service.accessLog = s.config.AccessLog
 
 	//	TODO: add logs to temporary logs location.
 
@@ -0,0 +1,145 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+//	Lines of unchanged context around changes in a diff
+const DIFF_CONTEXT = 3
+
+//	Compares two texts line by line, and returns their differences
+//	in the unified diff format. Returns an empty string if they are equal.
+func Diff(fromName, toName, from, to string) string {
+
+	if from == to {
+		return ""
+	}
+
+	a, b := splitLines(from), splitLines(to)
+
+	//	Lengths of longest common subsequences of all suffixes
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type edit struct {
+		kind byte
+		text string
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		default:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		}
+	}
+
+	var response strings.Builder
+	fmt.Fprintf(&response, "--- %s\n+++ %s\n", fromName, toName)
+
+	//	Group changes, which are close to each other, into hunks
+	for start := 0; start < len(edits); {
+
+		first := start
+		for first < len(edits) && edits[first].kind == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+
+		begin := first - DIFF_CONTEXT
+		if begin < 0 {
+			begin = 0
+		}
+
+		end := first
+		for unchanged := 0; end < len(edits) && unchanged <= 2*DIFF_CONTEXT; end++ {
+			if edits[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+
+		//	Trim trailing context beyond the limit
+		for end > first && edits[end-1].kind == ' ' {
+			end--
+		}
+		end += DIFF_CONTEXT
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		//	Line numbers of the hunk, in both texts
+		fromLine, toLine := 1, 1
+		for _, item := range edits[:begin] {
+			if item.kind != '+' {
+				fromLine++
+			}
+			if item.kind != '-' {
+				toLine++
+			}
+		}
+
+		fromCount, toCount := 0, 0
+		var lines strings.Builder
+		for _, item := range edits[begin:end] {
+			if item.kind != '+' {
+				fromCount++
+			}
+			if item.kind != '-' {
+				toCount++
+			}
+			fmt.Fprintf(&lines, "%c%s\n", item.kind, item.text)
+		}
+
+		fmt.Fprintf(&response, "@@ -%s +%s @@\n%s", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount), lines.String())
+
+		start = end
+	}
+
+	return response.String()
+}
+
+//	Formats the start and length of a hunk
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+//	Splits the text into lines, without the trailing empty one
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+// +build !windows
+
+package util
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+//	Starts the command in it's own session,
+//	so that it keeps running after the parent terminal exits.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+//	Checks whether a process with given PID is still alive.
+func ProcessRunning(pid int) bool {
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	//	Signal 0 performs error checking only,
+	//	without actually sending a signal to the process.
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+//	Asks the process with given PID to gracefully shut down.
+func Terminate(pid int) error {
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+
+	return process.Signal(syscall.SIGTERM)
+}
+
+//	Notifies the channel whenever the terminal is resized.
+func NotifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
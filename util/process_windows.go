@@ -0,0 +1,46 @@
+//go:build windows
+// +build windows
+
+package util
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+//	Starts the command in a new process group,
+//	so that it keeps running after the parent terminal exits.
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+//	Checks whether a process with given PID is still alive.
+func ProcessRunning(pid int) bool {
+
+	//	On Windows, FindProcess opens a handle to the process,
+	//	and fails if no such process exists.
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	process.Release()
+	return true
+}
+
+//	Windows doesn't support sending SIGTERM to other processes,
+//	so the process is killed instead.
+func Terminate(pid int) error {
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+
+	return process.Kill()
+}
+
+//	Notifies the channel whenever the terminal is resized.
+//	Windows doesn't signal resizing, so the initial size is kept.
+func NotifyResize(c chan<- os.Signal) {}
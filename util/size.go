@@ -0,0 +1,48 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//	Formats the size in bytes for humans. Example: 1.5 MB
+func HumanSize(size int64) string {
+
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+//	Parses a human readable size into bytes. Example: 500MB, 2 GB, 1.5G
+func ParseSize(value string) (int64, error) {
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+	number := strings.TrimRight(strings.TrimSuffix(value, "B"), "KMGTPE ")
+
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+
+	unit := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, number), "B"))
+	if unit != "" {
+		exp := strings.Index("KMGTPE", unit)
+		if len(unit) != 1 || exp < 0 {
+			return 0, fmt.Errorf("invalid size unit: %s", value)
+		}
+		size *= math.Pow(1024, float64(exp+1))
+	}
+
+	return int64(size), nil
+}